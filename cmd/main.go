@@ -2,29 +2,180 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	logging "github.com/sirupsen/logrus"
 	"github.com/thought-machine/aws-service-quotas-exporter/serviceexporter"
+	"github.com/thought-machine/aws-service-quotas-exporter/servicequotas"
 )
 
 var log = logging.WithFields(logging.Fields{})
 
 var opts struct {
-	Port           int      `long:"port" short:"p" default:"9090" description:"Port on which to serve."`
-	Region         string   `long:"region" short:"r" env:"AWS_REGION" required:"true" description:"AWS region name"`
-	Profile        string   `long:"profile" short:"f" env:"AWS_PROFILE" default:"" description:"Named AWS profile to be used"`
-	RefreshPeriod  int      `long:"refresh-period" default:"360" description:"Refresh period in seconds"`
-	IncludeAWSTags []string `long:"include-aws-tag" description:"The aws resource tags to include as labels for returned metrics"`
+	Port                   int      `long:"port" short:"p" default:"9090" description:"Port on which to serve."`
+	Region                 string   `long:"region" short:"r" env:"AWS_REGION" required:"true" description:"AWS region name"`
+	Profile                string   `long:"profile" short:"f" env:"AWS_PROFILE" default:"" description:"Named AWS profile to be used"`
+	RefreshPeriod          int      `long:"refresh-period" default:"360" description:"Refresh period in seconds"`
+	IncludeAWSTags         []string `long:"include-aws-tag" description:"The aws resource tags to include as labels for returned metrics"`
+	ProbeRoleName          string   `long:"probe-role-name" description:"Name of the IAM role assumed in the accounts requested on /probe"`
+	ProbeCacheTTL          int      `long:"probe-cache-ttl" default:"300" description:"Time in seconds the results of /probe are cached for per target"`
+	DogStatsDAddr          string   `long:"dogstatsd-address" description:"Address (host:port) of a DogStatsD server to send quotas and usage to after every refresh"`
+	SubnetPrefixDelegation bool     `long:"subnet-prefix-delegation" description:"Additionally report the usage of subnets in /28 prefixes used by prefix delegation"`
+	VCPUBreakdown          bool     `long:"vcpu-breakdown" description:"Additionally report the vCPU usage per instance family and availability zone"`
+	VCPUBreakdownTags      []string `long:"vcpu-breakdown-tag" description:"The instance tags to additionally split the vCPU usage breakdown by"`
+	ENIBreakdown           bool     `long:"eni-breakdown" description:"Additionally report the network interfaces per region per interface type and requester"`
+}
+
+func quotasOptions() servicequotas.Options {
+	return servicequotas.Options{
+		SubnetPrefixDelegation:     opts.SubnetPrefixDelegation,
+		VCPUBreakdown:              opts.VCPUBreakdown,
+		VCPUBreakdownTags:          opts.VCPUBreakdownTags,
+		NetworkInterfacesBreakdown: opts.ENIBreakdown,
+	}
+}
+
+type generateCommand struct {
+	RulesFile          string        `long:"rules-file" default:"aws-service-quotas-rules.yaml" description:"File to write the Prometheus alerting rules to"`
+	PrometheusRuleName string        `long:"prometheus-rule-name" description:"Wrap the alerting rules in a PrometheusRule custom resource with this name"`
+	WarningThreshold   float64       `long:"warning-threshold" default:"0.8" description:"Utilization above which warning alerts fire"`
+	CriticalThreshold  float64       `long:"critical-threshold" default:"0.95" description:"Utilization above which critical alerts fire"`
+	For                time.Duration `long:"for" default:"15m" description:"How long an alert condition has to hold before firing"`
+	ForecastLookback   time.Duration `long:"forecast-lookback" default:"24h" description:"Range of usage history used for forecasting"`
+	ForecastWindow     time.Duration `long:"forecast-window" default:"168h" description:"How far ahead usage is forecast"`
+	DashboardFile      string        `long:"dashboard-file" default:"aws-service-quotas-dashboard.json" description:"File to write the Grafana dashboard to"`
+	DashboardTitle     string        `long:"dashboard-title" default:"AWS Service Quotas" description:"Title of the Grafana dashboard"`
+	DashboardUID       string        `long:"dashboard-uid" default:"aws-service-quotas" description:"UID of the Grafana dashboard"`
+}
+
+// validate returns an error if the thresholds are not utilizations
+// in (0, 1] or the durations are not positive
+func (c *generateCommand) validate() error {
+	thresholds := []struct {
+		name  string
+		value float64
+	}{
+		{name: "warning-threshold", value: c.WarningThreshold},
+		{name: "critical-threshold", value: c.CriticalThreshold},
+	}
+	for _, threshold := range thresholds {
+		if threshold.value <= 0 || threshold.value > 1 {
+			return fmt.Errorf("--%s must be in (0, 1], got %g", threshold.name, threshold.value)
+		}
+	}
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{name: "for", value: c.For},
+		{name: "forecast-lookback", value: c.ForecastLookback},
+		{name: "forecast-window", value: c.ForecastWindow},
+	}
+	for _, duration := range durations {
+		if duration.value <= 0 {
+			return fmt.Errorf("--%s must be positive, got %s", duration.name, duration.value)
+		}
+	}
+	return nil
+}
+
+// Execute writes the alerting rules and dashboard for the quotas
+// reported by the enabled usage checks
+func (c *generateCommand) Execute(args []string) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	quotas, err := servicequotas.NewServiceQuotasWithOptions(opts.Region, opts.Profile, quotasOptions())
+	if err != nil {
+		return err
+	}
+	definitions := quotas.Definitions()
+
+	rulesConfig := serviceexporter.RulesConfig{
+		WarningThreshold:   c.WarningThreshold,
+		CriticalThreshold:  c.CriticalThreshold,
+		For:                c.For,
+		ForecastLookback:   c.ForecastLookback,
+		ForecastWindow:     c.ForecastWindow,
+		PrometheusRuleName: c.PrometheusRuleName,
+	}
+	err = writeFile(c.RulesFile, func(f *os.File) error {
+		return serviceexporter.WriteAlertingRules(f, definitions, rulesConfig)
+	})
+	if err != nil {
+		return err
+	}
+	log.Infof("Wrote alerting rules to %s", c.RulesFile)
+
+	dashboardConfig := serviceexporter.DashboardConfig{Title: c.DashboardTitle, UID: c.DashboardUID}
+	err = writeFile(c.DashboardFile, func(f *os.File) error {
+		return serviceexporter.WriteDashboard(f, definitions, dashboardConfig)
+	})
+	if err != nil {
+		return err
+	}
+	log.Infof("Wrote dashboard to %s", c.DashboardFile)
+
+	return nil
+}
+
+func writeFile(path string, write func(*os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
 func main() {
-	flags.Parse(&opts)
-	quotasExporter, err := serviceexporter.NewServiceQuotasExporter(opts.Region, opts.Profile, opts.RefreshPeriod, opts.IncludeAWSTags)
+	parser := flags.NewParser(&opts, flags.Default)
+	parser.SubcommandsOptional = true
+	parser.AddCommand("generate", "Generate alerting rules and dashboards",
+		"Generate Prometheus alerting rules and a Grafana dashboard for the quotas exported by the enabled usage checks", &generateCommand{})
+
+	if _, err := parser.Parse(); err != nil {
+		var flagsErr *flags.Error
+		if errors.As(err, &flagsErr) && flagsErr.Type == flags.ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(1)
+	}
+
+	// The command has already been executed by the parser
+	if parser.Active != nil {
+		return
+	}
+
+	sinks := []serviceexporter.Sink{}
+	if opts.DogStatsDAddr != "" {
+		account, err := servicequotas.AccountID(opts.Region, opts.Profile)
+		if err != nil {
+			log.Fatalf("Failed to retrieve account ID: %s", err)
+		}
+
+		sink, err := serviceexporter.NewDogStatsDSink(opts.DogStatsDAddr, opts.Region, account, opts.IncludeAWSTags)
+		if err != nil {
+			log.Fatalf("Failed to create DogStatsD sink: %s", err)
+		}
+		log.Infof("Sending quotas and usage to DogStatsD on %s", opts.DogStatsDAddr)
+		sinks = append(sinks, sink)
+	}
+
+	quotasExporter, err := serviceexporter.NewServiceQuotasExporter(opts.Region, opts.Profile, opts.RefreshPeriod, opts.IncludeAWSTags, quotasOptions(), sinks...)
 	if err != nil {
 		log.Fatalf("Failed to create exporter: %s", err)
 	}
@@ -34,6 +185,13 @@ func main() {
 	log.Infof("Serving on port: %d", opts.Port)
 	log.Infof("Serving Prometheus metrics on /metrics")
 	http.Handle("/metrics", promhttp.Handler())
+	log.Infof("Serving quotas API on /api/v1/quotas")
+	http.Handle("/api/v1/quotas", serviceexporter.NewAPIHandler(quotasExporter))
+	log.Infof("Serving status page on /status")
+	http.Handle("/status", serviceexporter.NewStatusPageHandler(quotasExporter))
+	log.Infof("Serving multi-target probes on /probe")
+	probeCacheTTL := time.Duration(opts.ProbeCacheTTL) * time.Second
+	http.Handle("/probe", serviceexporter.NewProbeHandler(opts.Profile, opts.ProbeRoleName, probeCacheTTL, opts.IncludeAWSTags, quotasOptions()))
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "OK")
 	})
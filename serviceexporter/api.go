@@ -0,0 +1,206 @@
+package serviceexporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thought-machine/aws-service-quotas-exporter/servicequotas"
+)
+
+// snapshot is a copy of the state of the exporter's usage checks
+type snapshot struct {
+	region      string
+	lastRefresh time.Time
+	refreshErr  error
+	checks      []checkStatus
+}
+
+// snapshot returns a copy of the current state of the usage checks
+// sorted by the names of their quotas
+func (e *ServiceQuotasExporter) snapshot() snapshot {
+	e.metricsLock.Lock()
+	defer e.metricsLock.Unlock()
+
+	keys := make([]string, 0, len(e.checkStatuses))
+	for key := range e.checkStatuses {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	checks := make([]checkStatus, 0, len(keys))
+	for _, key := range keys {
+		checks = append(checks, e.checkStatuses[key])
+	}
+
+	return snapshot{
+		region:      e.metricsRegion,
+		lastRefresh: e.lastRefresh,
+		refreshErr:  e.refreshErr,
+		checks:      checks,
+	}
+}
+
+func utilization(quota servicequotas.QuotaUsage) float64 {
+	if quota.Quota <= 0 {
+		return 0
+	}
+	return quota.Usage / quota.Quota
+}
+
+type apiQuota struct {
+	Name        string            `json:"name"`
+	Resource    string            `json:"resource"`
+	Description string            `json:"description"`
+	Usage       float64           `json:"usage"`
+	Limit       float64           `json:"limit"`
+	Utilization float64           `json:"utilization"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	LastRefresh time.Time         `json:"last_refresh"`
+}
+
+type apiError struct {
+	Quotas []string `json:"quotas"`
+	Error  string   `json:"error"`
+}
+
+type apiResponse struct {
+	Region      string     `json:"region"`
+	LastRefresh time.Time  `json:"last_refresh"`
+	Errors      []apiError `json:"errors"`
+	Quotas      []apiQuota `json:"quotas"`
+}
+
+// quotaFilter selects the quotas returned by the API
+type quotaFilter struct {
+	names             map[string]bool
+	resource          string
+	tags              map[string]string
+	utilizationAbove  float64
+	filterUtilization bool
+}
+
+func newQuotaFilter(r *http.Request) (quotaFilter, error) {
+	query := r.URL.Query()
+	filter := quotaFilter{
+		names:    map[string]bool{},
+		resource: query.Get("resource"),
+		tags:     map[string]string{},
+	}
+
+	for _, value := range query["name"] {
+		for _, name := range strings.Split(value, ",") {
+			if name != "" {
+				filter.names[name] = true
+			}
+		}
+	}
+
+	for _, tag := range query["tag"] {
+		key, value, ok := strings.Cut(tag, "=")
+		if !ok {
+			return filter, fmt.Errorf("invalid tag filter (%s), expected key=value", tag)
+		}
+		filter.tags[servicequotas.ToPrometheusNamingFormat(key)] = value
+	}
+
+	if value := query.Get("utilization_above"); value != "" {
+		utilizationAbove, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid utilization_above filter (%s): %w", value, err)
+		}
+		filter.utilizationAbove = utilizationAbove
+		filter.filterUtilization = true
+	}
+
+	return filter, nil
+}
+
+func (f quotaFilter) matches(quota servicequotas.QuotaUsage) bool {
+	if len(f.names) > 0 && !f.names[quota.Name] {
+		return false
+	}
+
+	if f.resource != "" && f.resource != quota.Identifier() {
+		return false
+	}
+
+	for key, value := range f.tags {
+		if tagValue, ok := quota.Tags[key]; !ok || tagValue != value {
+			return false
+		}
+	}
+
+	return !f.filterUtilization || utilization(quota) > f.utilizationAbove
+}
+
+// APIHandler serves the current quotas and usage as JSON. Quotas can
+// be filtered with the `name`, `resource`, `tag` (key=value) and
+// `utilization_above` query parameters
+type APIHandler struct {
+	exporter *ServiceQuotasExporter
+}
+
+// NewAPIHandler creates an APIHandler serving the state of `exporter`
+func NewAPIHandler(exporter *ServiceQuotasExporter) *APIHandler {
+	return &APIHandler{exporter: exporter}
+}
+
+// ServeHTTP serves the quotas matching the request's filters
+func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filter, err := newQuotaFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	current := h.exporter.snapshot()
+	response := apiResponse{
+		Region:      current.region,
+		LastRefresh: current.lastRefresh,
+		Errors:      []apiError{},
+		Quotas:      []apiQuota{},
+	}
+
+	if current.refreshErr != nil {
+		response.Errors = append(response.Errors, apiError{Quotas: []string{}, Error: current.refreshErr.Error()})
+	}
+
+	for _, check := range current.checks {
+		if check.err != nil {
+			names := []string{}
+			for _, definition := range check.definitions {
+				names = append(names, definition.Name)
+			}
+			response.Errors = append(response.Errors, apiError{Quotas: names, Error: check.err.Error()})
+		}
+
+		for _, quota := range check.usages {
+			if !filter.matches(quota) {
+				continue
+			}
+
+			response.Quotas = append(response.Quotas, apiQuota{
+				Name:        quota.Name,
+				Resource:    quota.Identifier(),
+				Description: quota.Description,
+				Usage:       quota.Usage,
+				Limit:       quota.Quota,
+				Utilization: utilization(quota),
+				Labels:      quota.Labels,
+				Tags:        quota.Tags,
+				LastRefresh: check.lastRefresh,
+			})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Errorf("Failed to write quotas API response: %s", err)
+	}
+}
@@ -0,0 +1,138 @@
+package serviceexporter
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thought-machine/aws-service-quotas-exporter/servicequotas"
+)
+
+func newTestAPIExporter() *ServiceQuotasExporter {
+	refreshed := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	return &ServiceQuotasExporter{
+		metricsRegion: "eu-west-1",
+		metricsLock:   &sync.Mutex{},
+		lastRefresh:   refreshed,
+		checkStatuses: map[string]checkStatus{
+			"rules": {
+				definitions: []servicequotas.QuotaDefinition{{Name: "rules"}},
+				usages: []servicequotas.QuotaUsage{
+					{Name: "rules", ResourceName: resourceName("sg-1"), Usage: 45, Quota: 50, Tags: map[string]string{"team": "a"}},
+					{Name: "rules", ResourceName: resourceName("sg-2"), Usage: 10, Quota: 50, Tags: map[string]string{"team": "b"}},
+				},
+				lastRefresh: refreshed,
+			},
+			"spot": {
+				definitions: []servicequotas.QuotaDefinition{{Name: "spot"}},
+				usages:      []servicequotas.QuotaUsage{{Name: "spot", Usage: 0, Quota: 0}},
+				lastRefresh: refreshed.Add(-time.Hour),
+				err:         errors.New("some err"),
+			},
+		},
+	}
+}
+
+func queryAPI(t *testing.T, exporter *ServiceQuotasExporter, url string) (int, apiResponse) {
+	recorder := httptest.NewRecorder()
+	NewAPIHandler(exporter).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+
+	var response apiResponse
+	if recorder.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	}
+	return recorder.Code, response
+}
+
+func TestAPI(t *testing.T) {
+	code, response := queryAPI(t, newTestAPIExporter(), "/api/v1/quotas")
+
+	refreshed := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	expectedResponse := apiResponse{
+		Region:      "eu-west-1",
+		LastRefresh: refreshed,
+		Errors:      []apiError{{Quotas: []string{"spot"}, Error: "some err"}},
+		Quotas: []apiQuota{
+			{Name: "rules", Resource: "sg-1", Usage: 45, Limit: 50, Utilization: 0.9, Tags: map[string]string{"team": "a"}, LastRefresh: refreshed},
+			{Name: "rules", Resource: "sg-2", Usage: 10, Limit: 50, Utilization: 0.2, Tags: map[string]string{"team": "b"}, LastRefresh: refreshed},
+			{Name: "spot", Resource: "spot", LastRefresh: refreshed.Add(-time.Hour)},
+		},
+	}
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, expectedResponse, response)
+}
+
+func TestAPIFilters(t *testing.T) {
+	testCases := []struct {
+		name              string
+		url               string
+		expectedResources []string
+	}{
+		{
+			name:              "ByName",
+			url:               "/api/v1/quotas?name=spot",
+			expectedResources: []string{"spot"},
+		},
+		{
+			name:              "ByResource",
+			url:               "/api/v1/quotas?resource=sg-2",
+			expectedResources: []string{"sg-2"},
+		},
+		{
+			name:              "ByTag",
+			url:               "/api/v1/quotas?tag=team=a",
+			expectedResources: []string{"sg-1"},
+		},
+		{
+			name:              "ByUtilization",
+			url:               "/api/v1/quotas?utilization_above=0.5",
+			expectedResources: []string{"sg-1"},
+		},
+		{
+			name:              "WithNoMatches",
+			url:               "/api/v1/quotas?name=rules&tag=team=c",
+			expectedResources: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, response := queryAPI(t, newTestAPIExporter(), tc.url)
+
+			resources := []string{}
+			for _, quota := range response.Quotas {
+				resources = append(resources, quota.Resource)
+			}
+
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, tc.expectedResources, resources)
+		})
+	}
+}
+
+func TestAPIWithInvalidFilters(t *testing.T) {
+	for _, url := range []string{"/api/v1/quotas?tag=team", "/api/v1/quotas?utilization_above=high"} {
+		code, _ := queryAPI(t, newTestAPIExporter(), url)
+		assert.Equal(t, http.StatusBadRequest, code)
+	}
+}
+
+func TestAPIWithRefreshError(t *testing.T) {
+	exporter := newTestAPIExporter()
+	exporter.checkStatuses = nil
+	exporter.refreshErr = errors.New("failed to list quotas")
+
+	code, response := queryAPI(t, exporter, "/api/v1/quotas")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []apiError{{Quotas: []string{}, Error: "failed to list quotas"}}, response.Errors)
+	assert.Equal(t, []apiQuota{}, response.Quotas)
+}
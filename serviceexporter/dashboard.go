@@ -0,0 +1,156 @@
+package serviceexporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/thought-machine/aws-service-quotas-exporter/servicequotas"
+)
+
+const (
+	dashboardPanelWidth  = 12
+	dashboardPanelHeight = 8
+	dashboardColumns     = 24 / dashboardPanelWidth
+)
+
+// DashboardConfig configures the dashboard generated by WriteDashboard
+type DashboardConfig struct {
+	// Title is the title of the dashboard
+	Title string
+	// UID is the unique identifier of the dashboard in Grafana
+	UID string
+}
+
+type dashboard struct {
+	UID           string              `json:"uid,omitempty"`
+	Title         string              `json:"title"`
+	Tags          []string            `json:"tags"`
+	SchemaVersion int                 `json:"schemaVersion"`
+	Editable      bool                `json:"editable"`
+	Refresh       string              `json:"refresh"`
+	Time          dashboardTime       `json:"time"`
+	Templating    dashboardTemplating `json:"templating"`
+	Panels        []dashboardPanel    `json:"panels"`
+}
+
+type dashboardTime struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type dashboardTemplating struct {
+	List []dashboardVariable `json:"list"`
+}
+
+type dashboardVariable struct {
+	Name       string               `json:"name"`
+	Label      string               `json:"label"`
+	Type       string               `json:"type"`
+	Query      string               `json:"query"`
+	Datasource *dashboardDatasource `json:"datasource,omitempty"`
+	Multi      bool                 `json:"multi,omitempty"`
+	IncludeAll bool                 `json:"includeAll,omitempty"`
+	Refresh    int                  `json:"refresh,omitempty"`
+}
+
+type dashboardDatasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type dashboardGridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type dashboardTarget struct {
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+	RefID        string `json:"refId"`
+}
+
+type dashboardPanel struct {
+	ID          int                 `json:"id"`
+	Type        string              `json:"type"`
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Datasource  dashboardDatasource `json:"datasource"`
+	GridPos     dashboardGridPos    `json:"gridPos"`
+	Targets     []dashboardTarget   `json:"targets"`
+}
+
+// WriteDashboard writes a Grafana dashboard with a panel for the used
+// amount and limit of every quota in `definitions` to `w` as JSON.
+// Breakdowns are skipped as they have no limit
+func WriteDashboard(w io.Writer, definitions []servicequotas.QuotaDefinition, config DashboardConfig) error {
+	definitions = limitedDefinitions(definitions)
+	datasource := dashboardDatasource{Type: "prometheus", UID: "${datasource}"}
+
+	regionQuery := "label_values(region)"
+	if len(definitions) > 0 {
+		regionQuery = fmt.Sprintf("label_values(%s, region)", fqName(definitions[0].Name, limitMetricName))
+	}
+
+	out := dashboard{
+		UID:           config.UID,
+		Title:         config.Title,
+		Tags:          []string{"aws", "service-quotas"},
+		SchemaVersion: 37,
+		Editable:      true,
+		Refresh:       "5m",
+		Time:          dashboardTime{From: "now-7d", To: "now"},
+		Templating: dashboardTemplating{
+			List: []dashboardVariable{
+				{Name: "datasource", Label: "Data source", Type: "datasource", Query: "prometheus"},
+				{
+					Name:       "region",
+					Label:      "Region",
+					Type:       "query",
+					Query:      regionQuery,
+					Datasource: &datasource,
+					Multi:      true,
+					IncludeAll: true,
+					Refresh:    2,
+				},
+			},
+		},
+		Panels: []dashboardPanel{},
+	}
+
+	for i, definition := range definitions {
+		selector := `{region=~"$region"}`
+		panel := dashboardPanel{
+			ID:          i + 1,
+			Type:        "timeseries",
+			Title:       definition.Name,
+			Description: definition.Description,
+			Datasource:  datasource,
+			GridPos: dashboardGridPos{
+				H: dashboardPanelHeight,
+				W: dashboardPanelWidth,
+				X: (i % dashboardColumns) * dashboardPanelWidth,
+				Y: (i / dashboardColumns) * dashboardPanelHeight,
+			},
+			Targets: []dashboardTarget{
+				{
+					Expr:         fqName(definition.Name, usedMetricName) + selector,
+					LegendFormat: "{{resource}} used",
+					RefID:        "A",
+				},
+				{
+					Expr:         fqName(definition.Name, limitMetricName) + selector,
+					LegendFormat: "{{resource}} limit",
+					RefID:        "B",
+				},
+			},
+		}
+		out.Panels = append(out.Panels, panel)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
@@ -0,0 +1,49 @@
+package serviceexporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thought-machine/aws-service-quotas-exporter/servicequotas"
+)
+
+func TestWriteDashboard(t *testing.T) {
+	definitions := []servicequotas.QuotaDefinition{
+		{Name: "spot_instance_requests_breakdown", Description: "spot instance requests by instance family", Breakdown: true},
+		{Name: "spot_instance_requests", Description: "spot instance requests"},
+		{Name: "security_groups_per_region", Description: "security groups per region"},
+		{Name: "available_ips_per_subnet", Description: "available IPs per subnet"},
+	}
+
+	var out bytes.Buffer
+	err := WriteDashboard(&out, definitions, DashboardConfig{Title: "Quotas", UID: "quotas"})
+	assert.NoError(t, err)
+
+	var actual dashboard
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &actual))
+
+	assert.Equal(t, "Quotas", actual.Title)
+	assert.Equal(t, "quotas", actual.UID)
+	assert.Equal(t, "label_values(aws_spot_instance_requests_limit_total, region)", actual.Templating.List[1].Query)
+	assert.Len(t, actual.Panels, 3)
+
+	expectedTitles := []string{"spot_instance_requests", "security_groups_per_region", "available_ips_per_subnet"}
+	expectedGridPos := []dashboardGridPos{
+		{H: 8, W: 12, X: 0, Y: 0},
+		{H: 8, W: 12, X: 12, Y: 0},
+		{H: 8, W: 12, X: 0, Y: 8},
+	}
+	for i, panel := range actual.Panels {
+		assert.Equal(t, expectedTitles[i], panel.Title)
+		assert.Equal(t, expectedGridPos[i], panel.GridPos)
+	}
+
+	expectedTargets := []dashboardTarget{
+		{Expr: `aws_spot_instance_requests_used_total{region=~"$region"}`, LegendFormat: "{{resource}} used", RefID: "A"},
+		{Expr: `aws_spot_instance_requests_limit_total{region=~"$region"}`, LegendFormat: "{{resource}} limit", RefID: "B"},
+	}
+	assert.Equal(t, expectedTargets, actual.Panels[0].Targets)
+}
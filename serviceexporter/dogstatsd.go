@@ -0,0 +1,105 @@
+package serviceexporter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/thought-machine/aws-service-quotas-exporter/servicequotas"
+)
+
+// maxDogStatsDPacketSize keeps packets below the common network MTU
+// so they are not fragmented
+const maxDogStatsDPacketSize = 1432
+
+var dogStatsDTagReplacer = strings.NewReplacer(",", "_", "|", "_", "#", "_", "\n", "_")
+
+// Sink receives the quotas and usage after every refresh
+type Sink interface {
+	// Send sends the quotas and usage of a refresh
+	Send(quotas []servicequotas.QuotaUsage) error
+}
+
+// DogStatsDSink sends the usage, limit and utilization of quotas as
+// DogStatsD gauges
+type DogStatsDSink struct {
+	conn            io.Writer
+	tags            []string
+	includedAWSTags []string
+}
+
+// NewDogStatsDSink creates a DogStatsDSink sending to the DogStatsD
+// server at `address`. Gauges are tagged with `region`, `account`
+// and the AWS tags in `includedAWSTags`
+func NewDogStatsDSink(address, region, account string, includedAWSTags []string) (*DogStatsDSink, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DogStatsDSink{
+		conn:            conn,
+		tags:            []string{dogStatsDTag("region", region), dogStatsDTag("account", account)},
+		includedAWSTags: includedAWSTags,
+	}, nil
+}
+
+func dogStatsDTag(key, value string) string {
+	return fmt.Sprintf("%s:%s", servicequotas.ToPrometheusNamingFormat(key), dogStatsDTagReplacer.Replace(value))
+}
+
+// Send sends a gauge for the usage, limit and utilization of every
+// quota in `quotas`, batching them into as few packets as possible
+func (s *DogStatsDSink) Send(quotas []servicequotas.QuotaUsage) error {
+	var packet bytes.Buffer
+
+	for _, quota := range quotas {
+		tags := append([]string{dogStatsDTag("resource", quota.Identifier())}, s.tags...)
+		for _, label := range quotaLabelNames(quota) {
+			tags = append(tags, dogStatsDTag(label, quota.Labels[label]))
+		}
+		for _, tag := range s.includedAWSTags {
+			key := servicequotas.ToPrometheusNamingFormat(tag)
+			if value, ok := quota.Tags[key]; ok {
+				tags = append(tags, dogStatsDTag(key, value))
+			}
+		}
+		joinedTags := strings.Join(tags, ",")
+
+		gauges := []struct {
+			name  string
+			value float64
+		}{
+			{name: "used", value: quota.Usage},
+			{name: "limit", value: quota.Quota},
+			{name: "utilization", value: utilization(quota)},
+		}
+
+		for _, gauge := range gauges {
+			line := fmt.Sprintf("aws.%s.%s:%s|g|#%s\n",
+				quota.Name, gauge.name, strconv.FormatFloat(gauge.value, 'f', -1, 64), joinedTags)
+
+			if packet.Len() > 0 && packet.Len()+len(line) > maxDogStatsDPacketSize {
+				if err := s.flush(&packet); err != nil {
+					return err
+				}
+			}
+			packet.WriteString(line)
+		}
+	}
+
+	return s.flush(&packet)
+}
+
+func (s *DogStatsDSink) flush(packet *bytes.Buffer) error {
+	if packet.Len() == 0 {
+		return nil
+	}
+
+	_, err := s.conn.Write(bytes.TrimSuffix(packet.Bytes(), []byte("\n")))
+	packet.Reset()
+	return err
+}
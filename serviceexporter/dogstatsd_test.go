@@ -0,0 +1,112 @@
+package serviceexporter
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thought-machine/aws-service-quotas-exporter/servicequotas"
+)
+
+type packetRecorder struct {
+	packets []string
+	err     error
+}
+
+func (r *packetRecorder) Write(p []byte) (int, error) {
+	r.packets = append(r.packets, string(p))
+	return len(p), r.err
+}
+
+type sinkMock struct {
+	sent [][]servicequotas.QuotaUsage
+}
+
+func (s *sinkMock) Send(quotas []servicequotas.QuotaUsage) error {
+	s.sent = append(s.sent, quotas)
+	return nil
+}
+
+func TestDogStatsDSinkSend(t *testing.T) {
+	recorder := &packetRecorder{}
+	sink := &DogStatsDSink{
+		conn:            recorder,
+		tags:            []string{dogStatsDTag("region", "eu-west-1"), dogStatsDTag("account", "123456789012")},
+		includedAWSTags: []string{"Team", "dummy-tag"},
+	}
+
+	err := sink.Send([]servicequotas.QuotaUsage{
+		{
+			Name:         "rules",
+			ResourceName: resourceName("sg-1"),
+			Usage:        45,
+			Quota:        50,
+			Tags:         map[string]string{"team": "a,b|c", "other": "ignored"},
+		},
+		{Name: "spot_instance_requests", Usage: 1.5, Quota: 0},
+	})
+
+	expectedPackets := []string{
+		"aws.rules.used:45|g|#resource:sg-1,region:eu-west-1,account:123456789012,team:a_b_c\n" +
+			"aws.rules.limit:50|g|#resource:sg-1,region:eu-west-1,account:123456789012,team:a_b_c\n" +
+			"aws.rules.utilization:0.9|g|#resource:sg-1,region:eu-west-1,account:123456789012,team:a_b_c\n" +
+			"aws.spot_instance_requests.used:1.5|g|#resource:spot_instance_requests,region:eu-west-1,account:123456789012\n" +
+			"aws.spot_instance_requests.limit:0|g|#resource:spot_instance_requests,region:eu-west-1,account:123456789012\n" +
+			"aws.spot_instance_requests.utilization:0|g|#resource:spot_instance_requests,region:eu-west-1,account:123456789012",
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedPackets, recorder.packets)
+}
+
+func TestDogStatsDSinkSendBatchesPackets(t *testing.T) {
+	recorder := &packetRecorder{}
+	sink := &DogStatsDSink{conn: recorder}
+
+	quotas := []servicequotas.QuotaUsage{}
+	for i := 0; i < 100; i++ {
+		quotas = append(quotas, servicequotas.QuotaUsage{Name: "some_quota", ResourceName: resourceName("some-resource"), Usage: 1, Quota: 2})
+	}
+
+	err := sink.Send(quotas)
+
+	assert.NoError(t, err)
+	assert.Greater(t, len(recorder.packets), 1)
+
+	lines := 0
+	for _, packet := range recorder.packets {
+		assert.LessOrEqual(t, len(packet), maxDogStatsDPacketSize)
+		lines += len(strings.Split(packet, "\n"))
+	}
+	assert.Equal(t, 300, lines)
+}
+
+func TestDogStatsDSinkSendWithError(t *testing.T) {
+	recorder := &packetRecorder{err: errors.New("some err")}
+	sink := &DogStatsDSink{conn: recorder}
+
+	err := sink.Send([]servicequotas.QuotaUsage{{Name: "some_quota"}})
+
+	assert.Error(t, err)
+}
+
+func TestRefreshSendsToSinks(t *testing.T) {
+	quotas := []servicequotas.QuotaUsage{{ResourceName: resourceName("i-asdasd1"), Usage: 5, Quota: 10}}
+	sink := &sinkMock{}
+
+	exporter := &ServiceQuotasExporter{
+		metricsRegion: "eu-west-1",
+		quotasClient:  &ServiceQuotasMock{quotas: quotas},
+		metrics:       map[string]Metric{},
+		metricsLock:   &sync.Mutex{},
+		refreshPeriod: 360,
+		sinks:         []Sink{sink},
+	}
+
+	exporter.createOrUpdateQuotasAndDescriptions(true)
+
+	assert.Equal(t, [][]servicequotas.QuotaUsage{quotas}, sink.sent)
+}
@@ -0,0 +1,234 @@
+package serviceexporter
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/thought-machine/aws-service-quotas-exporter/servicequotas"
+)
+
+// maxProbeTargets is the number of probed targets kept, beyond which
+// the least recently probed target is evicted
+const maxProbeTargets = 100
+
+// accountIDPattern matches valid AWS account IDs
+var accountIDPattern = regexp.MustCompile(`^[0-9]{12}$`)
+
+type quotasClientFactory func(region, profile string, options servicequotas.Options) (servicequotas.QuotasInterface, error)
+
+// ProbeHandler serves quotas and usage for the account, region and
+// checks given as query parameters, similar to the blackbox exporter,
+// so that the scraped targets are driven by Prometheus service discovery
+type ProbeHandler struct {
+	profile         string
+	roleName        string
+	cacheTTL        time.Duration
+	includedAWSTags []string
+	quotasOptions   servicequotas.Options
+	newQuotasClient quotasClientFactory
+	maxTargets      int
+	targets         map[string]*probeTarget
+	targetsLock     *sync.Mutex
+}
+
+// probeTarget holds the cached quotas and usage of a probed target
+type probeTarget struct {
+	quotasClient servicequotas.QuotasInterface
+	quotas       []servicequotas.QuotaUsage
+	expiry       time.Time
+	lock         *sync.Mutex
+	// lastProbe is when the target was last probed, guarded by the
+	// ProbeHandler's targetsLock
+	lastProbe time.Time
+}
+
+// NewProbeHandler creates a ProbeHandler using `profile`. Accounts
+// other than the one of the profile are probed by assuming the role
+// named `roleName` in them. Results are cached per target for
+// `cacheTTL`, for up to maxProbeTargets targets. The usage checks are
+// configured by `quotasOptions`, with the role and checks set per
+// target
+func NewProbeHandler(profile, roleName string, cacheTTL time.Duration, includedAWSTags []string, quotasOptions servicequotas.Options) *ProbeHandler {
+	return &ProbeHandler{
+		profile:         profile,
+		roleName:        roleName,
+		cacheTTL:        cacheTTL,
+		includedAWSTags: includedAWSTags,
+		quotasOptions:   quotasOptions,
+		newQuotasClient: servicequotas.NewServiceQuotasWithOptions,
+		maxTargets:      maxProbeTargets,
+		targets:         map[string]*probeTarget{},
+		targetsLock:     &sync.Mutex{},
+	}
+}
+
+// ServeHTTP serves the quotas and usage of the probed target in the
+// Prometheus exposition format
+func (h *ProbeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	account := query.Get("account")
+	region := query.Get("region")
+	if region == "" {
+		http.Error(w, "region parameter is required", http.StatusBadRequest)
+		return
+	}
+	if account != "" && !accountIDPattern.MatchString(account) {
+		http.Error(w, fmt.Sprintf("invalid account (%s), expected a 12 digit account ID", account), http.StatusBadRequest)
+		return
+	}
+
+	checks := []string{}
+	for _, value := range query["checks"] {
+		for _, check := range strings.Split(value, ",") {
+			if check != "" {
+				checks = append(checks, check)
+			}
+		}
+	}
+	sort.Strings(checks)
+
+	target, err := h.target(account, region, checks)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	quotas, err := target.quotasAndUsage(h.cacheTTL)
+	if err != nil {
+		log.Errorf("Failed to probe account (%s) in region (%s): %s", account, region, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(&quotasCollector{region: region, quotas: quotas, includedAWSTags: h.includedAWSTags})
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
+
+// target returns the cached target for `account`, `region` and
+// `checks` or creates a new one, evicting the least recently probed
+// target if there are too many. An error is returned if the target
+// can not be created or any of `checks` is unknown
+func (h *ProbeHandler) target(account, region string, checks []string) (*probeTarget, error) {
+	key := fmt.Sprintf("%s/%s/%s", account, region, strings.Join(checks, ","))
+
+	h.targetsLock.Lock()
+	defer h.targetsLock.Unlock()
+
+	if target, ok := h.targets[key]; ok {
+		target.lastProbe = time.Now()
+		return target, nil
+	}
+
+	options := h.quotasOptions
+	options.Checks = checks
+	if account != "" {
+		if h.roleName == "" {
+			return nil, fmt.Errorf("a role name is required to probe account (%s)", account)
+		}
+
+		roleARN, err := servicequotas.RoleARN(region, account, h.roleName)
+		if err != nil {
+			return nil, err
+		}
+		options.RoleARN = roleARN
+	}
+
+	quotasClient, err := h.newQuotasClient(region, h.profile, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if unknown := unknownChecks(quotasClient, checks); len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown checks (%s) in region (%s)", strings.Join(unknown, ","), region)
+	}
+
+	if len(h.targets) >= h.maxTargets {
+		h.evictLeastRecentlyProbed()
+	}
+
+	target := &probeTarget{quotasClient: quotasClient, lock: &sync.Mutex{}, lastProbe: time.Now()}
+	h.targets[key] = target
+	return target, nil
+}
+
+// evictLeastRecentlyProbed removes the target that was probed least
+// recently. It must be called with the targetsLock held
+func (h *ProbeHandler) evictLeastRecentlyProbed() {
+	var oldestKey string
+	var oldest time.Time
+	for key, target := range h.targets {
+		if oldestKey == "" || target.lastProbe.Before(oldest) {
+			oldestKey = key
+			oldest = target.lastProbe
+		}
+	}
+	delete(h.targets, oldestKey)
+}
+
+// unknownChecks returns the names in `checks` that none of the usage
+// checks of `quotasClient` report
+func unknownChecks(quotasClient servicequotas.QuotasInterface, checks []string) []string {
+	known := map[string]bool{}
+	for _, definition := range quotasClient.Definitions() {
+		known[definition.Name] = true
+	}
+
+	unknown := []string{}
+	for _, check := range checks {
+		if !known[check] {
+			unknown = append(unknown, check)
+		}
+	}
+	return unknown
+}
+
+// quotasAndUsage returns the cached quotas and usage of the target or
+// retrieves them if they are older than `cacheTTL`
+func (t *probeTarget) quotasAndUsage(cacheTTL time.Duration) ([]servicequotas.QuotaUsage, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.quotas != nil && time.Now().Before(t.expiry) {
+		return t.quotas, nil
+	}
+
+	quotas, err := t.quotasClient.QuotasAndUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	t.quotas = quotas
+	t.expiry = time.Now().Add(cacheTTL)
+	return quotas, nil
+}
+
+// quotasCollector is a prometheus collector for a snapshot of quotas
+// and usage
+type quotasCollector struct {
+	region          string
+	quotas          []servicequotas.QuotaUsage
+	includedAWSTags []string
+}
+
+// Describe sends no descriptors, which makes quotasCollector an
+// unchecked collector as the quotas are only known when collecting
+func (c *quotasCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+// Collect implements the collect function for prometheus collectors
+func (c *quotasCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, quota := range c.quotas {
+		labels, labelValues := quotaLabels(quota, c.includedAWSTags)
+		usageDesc, limitDesc := quotaDescs(c.region, quota, labels)
+
+		ch <- prometheus.MustNewConstMetric(limitDesc, prometheus.GaugeValue, quota.Quota, labelValues...)
+		ch <- prometheus.MustNewConstMetric(usageDesc, prometheus.GaugeValue, quota.Usage, labelValues...)
+	}
+}
@@ -0,0 +1,209 @@
+package serviceexporter
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thought-machine/aws-service-quotas-exporter/servicequotas"
+)
+
+type probeClientsMock struct {
+	clients map[string]*ServiceQuotasMock
+	options []servicequotas.Options
+}
+
+func (m *probeClientsMock) newQuotasClient(region, profile string, options servicequotas.Options) (servicequotas.QuotasInterface, error) {
+	m.options = append(m.options, options)
+	client, ok := m.clients[region]
+	if !ok {
+		return nil, errors.New("unknown region")
+	}
+	return client, nil
+}
+
+func newTestProbeHandler(clients *probeClientsMock, roleName string, cacheTTL time.Duration) *ProbeHandler {
+	return &ProbeHandler{
+		roleName:        roleName,
+		cacheTTL:        cacheTTL,
+		includedAWSTags: []string{"dummy-tag"},
+		newQuotasClient: clients.newQuotasClient,
+		maxTargets:      maxProbeTargets,
+		targets:         map[string]*probeTarget{},
+		targetsLock:     &sync.Mutex{},
+	}
+}
+
+func probe(handler http.Handler, url string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+	return recorder
+}
+
+func TestProbe(t *testing.T) {
+	client := &ServiceQuotasMock{
+		quotas: []servicequotas.QuotaUsage{
+			{
+				Name:         "some_quota",
+				ResourceName: resourceName("i-asdasd1"),
+				Description:  "some quota",
+				Usage:        5,
+				Quota:        10,
+				Tags:         map[string]string{"dummy_tag": "dummy-value"},
+			},
+		},
+		definitions: []servicequotas.QuotaDefinition{
+			{Name: "some_quota", Description: "some quota"},
+			{Name: "other_quota", Description: "other quota"},
+		},
+	}
+	clients := &probeClientsMock{clients: map[string]*ServiceQuotasMock{"eu-west-1": client}}
+	handler := newTestProbeHandler(clients, "quotas", time.Hour)
+
+	response := probe(handler, "/probe?account=123456789012&region=eu-west-1&checks=some_quota,other_quota")
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `aws_some_quota_used_total{dummy_tag="dummy-value",region="eu-west-1",resource="i-asdasd1"} 5`)
+	assert.Contains(t, response.Body.String(), `aws_some_quota_limit_total{dummy_tag="dummy-value",region="eu-west-1",resource="i-asdasd1"} 10`)
+
+	expectedOptions := []servicequotas.Options{
+		{
+			RoleARN: "arn:aws:iam::123456789012:role/quotas",
+			Checks:  []string{"other_quota", "some_quota"},
+		},
+	}
+	assert.Equal(t, expectedOptions, clients.options)
+}
+
+func TestProbeCache(t *testing.T) {
+	testCases := []struct {
+		name                string
+		cacheTTL            time.Duration
+		expectedTimesCalled int
+	}{
+		{
+			name:                "WithinTTL",
+			cacheTTL:            time.Hour,
+			expectedTimesCalled: 1,
+		},
+		{
+			name:                "AfterTTL",
+			cacheTTL:            0,
+			expectedTimesCalled: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &ServiceQuotasMock{quotas: []servicequotas.QuotaUsage{}}
+			clients := &probeClientsMock{clients: map[string]*ServiceQuotasMock{"eu-west-1": client}}
+			handler := newTestProbeHandler(clients, "", tc.cacheTTL)
+
+			probe(handler, "/probe?region=eu-west-1")
+			probe(handler, "/probe?region=eu-west-1")
+
+			assert.Equal(t, tc.expectedTimesCalled, client.timesCalled)
+			assert.Len(t, clients.options, 1)
+		})
+	}
+}
+
+func TestProbeWithError(t *testing.T) {
+	client := &ServiceQuotasMock{
+		err:         errors.New("some err"),
+		definitions: []servicequotas.QuotaDefinition{{Name: "some_quota", Description: "some quota"}},
+	}
+	clients := &probeClientsMock{clients: map[string]*ServiceQuotasMock{"eu-west-1": client}}
+	handler := newTestProbeHandler(clients, "quotas", time.Hour)
+
+	testCases := []struct {
+		name         string
+		url          string
+		expectedCode int
+	}{
+		{
+			name:         "WithoutRegion",
+			url:          "/probe?account=123456789012",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "WithInvalidRegion",
+			url:          "/probe?region=asdasd",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "WithNonNumericAccount",
+			url:          "/probe?account=my-account&region=eu-west-1",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "WithShortAccount",
+			url:          "/probe?account=12345&region=eu-west-1",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "WithUnknownCheck",
+			url:          "/probe?region=eu-west-1&checks=some_quota,sme_quota",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "WithUsageError",
+			url:          "/probe?region=eu-west-1",
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := probe(handler, tc.url)
+
+			assert.Equal(t, tc.expectedCode, response.Code)
+		})
+	}
+}
+
+func TestProbeAccountWithoutRoleName(t *testing.T) {
+	clients := &probeClientsMock{clients: map[string]*ServiceQuotasMock{"eu-west-1": {}}}
+	handler := newTestProbeHandler(clients, "", time.Hour)
+
+	response := probe(handler, "/probe?account=123456789012&region=eu-west-1")
+
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Empty(t, clients.options)
+}
+
+func TestProbeUnknownCheckIsNotCached(t *testing.T) {
+	clients := &probeClientsMock{clients: map[string]*ServiceQuotasMock{"eu-west-1": {}}}
+	handler := newTestProbeHandler(clients, "", time.Hour)
+
+	probe(handler, "/probe?region=eu-west-1&checks=sme_quota")
+
+	assert.Empty(t, handler.targets)
+}
+
+func TestProbeEvictsLeastRecentlyProbedTarget(t *testing.T) {
+	clients := &probeClientsMock{
+		clients: map[string]*ServiceQuotasMock{
+			"eu-west-1": {quotas: []servicequotas.QuotaUsage{}},
+			"eu-west-2": {quotas: []servicequotas.QuotaUsage{}},
+			"us-east-1": {quotas: []servicequotas.QuotaUsage{}},
+		},
+	}
+	handler := newTestProbeHandler(clients, "", time.Hour)
+	handler.maxTargets = 2
+
+	probe(handler, "/probe?region=eu-west-1")
+	probe(handler, "/probe?region=eu-west-2")
+	probe(handler, "/probe?region=eu-west-1")
+	probe(handler, "/probe?region=us-east-1")
+
+	assert.Len(t, handler.targets, 2)
+	assert.Contains(t, handler.targets, "/eu-west-1/")
+	assert.Contains(t, handler.targets, "/us-east-1/")
+	assert.NotContains(t, handler.targets, "/eu-west-2/")
+}
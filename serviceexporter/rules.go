@@ -0,0 +1,169 @@
+package serviceexporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/thought-machine/aws-service-quotas-exporter/servicequotas"
+	"gopkg.in/yaml.v3"
+)
+
+const rulesGroupName = "aws-service-quotas"
+
+// RulesConfig configures the alerting rules generated by
+// WriteAlertingRules
+type RulesConfig struct {
+	// WarningThreshold is the utilization (usage / limit) above which
+	// a warning alert fires
+	WarningThreshold float64
+	// CriticalThreshold is the utilization (usage / limit) above which
+	// a critical alert fires
+	CriticalThreshold float64
+	// For is how long an alert condition has to hold before firing
+	For time.Duration
+	// ForecastLookback is the range of usage history the forecast is
+	// extrapolated from
+	ForecastLookback time.Duration
+	// ForecastWindow is how far ahead the usage is forecast
+	ForecastWindow time.Duration
+	// PrometheusRuleName is the name of the PrometheusRule custom
+	// resource the rules are wrapped in. A plain Prometheus rules
+	// file is generated when empty
+	PrometheusRuleName string
+}
+
+type ruleGroups struct {
+	Groups []ruleGroup `yaml:"groups"`
+}
+
+type ruleGroup struct {
+	Name  string         `yaml:"name"`
+	Rules []alertingRule `yaml:"rules"`
+}
+
+type alertingRule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+type prometheusRuleMetadata struct {
+	Name string `yaml:"name"`
+}
+
+type prometheusRule struct {
+	APIVersion string                 `yaml:"apiVersion"`
+	Kind       string                 `yaml:"kind"`
+	Metadata   prometheusRuleMetadata `yaml:"metadata"`
+	Spec       ruleGroups             `yaml:"spec"`
+}
+
+// WriteAlertingRules writes Prometheus alerting rules for the
+// utilization and forecast usage of every quota in `definitions` to
+// `w` as YAML. Breakdowns are skipped as they have no limit
+func WriteAlertingRules(w io.Writer, definitions []servicequotas.QuotaDefinition, config RulesConfig) error {
+	group := ruleGroup{Name: rulesGroupName, Rules: []alertingRule{}}
+	for _, definition := range limitedDefinitions(definitions) {
+		group.Rules = append(group.Rules, quotaAlertingRules(definition, config)...)
+	}
+
+	var out interface{} = ruleGroups{Groups: []ruleGroup{group}}
+	if config.PrometheusRuleName != "" {
+		out = prometheusRule{
+			APIVersion: "monitoring.coreos.com/v1",
+			Kind:       "PrometheusRule",
+			Metadata:   prometheusRuleMetadata{Name: config.PrometheusRuleName},
+			Spec:       ruleGroups{Groups: []ruleGroup{group}},
+		}
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(out); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func quotaAlertingRules(definition servicequotas.QuotaDefinition, config RulesConfig) []alertingRule {
+	used := fqName(definition.Name, usedMetricName)
+	limit := fqName(definition.Name, limitMetricName)
+	utilization := fmt.Sprintf("%s / (%s > 0)", used, limit)
+	forDuration := promDuration(config.For)
+
+	utilizationSummary := fmt.Sprintf("{{ $labels.resource }} in {{ $labels.region }} is using {{ $value | humanizePercentage }} of its %s limit", definition.Description)
+
+	return []alertingRule{
+		{
+			Alert: alertName(definition.Name, "CriticalUtilization"),
+			Expr:  fmt.Sprintf("%s >= %g", utilization, config.CriticalThreshold),
+			For:   forDuration,
+			Labels: map[string]string{
+				"severity": "critical",
+				"quota":    definition.Name,
+			},
+			Annotations: map[string]string{"summary": utilizationSummary},
+		},
+		{
+			Alert: alertName(definition.Name, "HighUtilization"),
+			Expr:  fmt.Sprintf("%s >= %g", utilization, config.WarningThreshold),
+			For:   forDuration,
+			Labels: map[string]string{
+				"severity": "warning",
+				"quota":    definition.Name,
+			},
+			Annotations: map[string]string{"summary": utilizationSummary},
+		},
+		{
+			Alert: alertName(definition.Name, "ForecastExhaustion"),
+			Expr: fmt.Sprintf("predict_linear(%s[%s], %d) >= (%s > 0)",
+				used, promDuration(config.ForecastLookback), int64(config.ForecastWindow.Seconds()), limit),
+			For: forDuration,
+			Labels: map[string]string{
+				"severity": "warning",
+				"quota":    definition.Name,
+			},
+			Annotations: map[string]string{
+				"summary": fmt.Sprintf("{{ $labels.resource }} in {{ $labels.region }} is forecast to reach its %s limit within %s",
+					definition.Description, promDuration(config.ForecastWindow)),
+			},
+		},
+	}
+}
+
+// alertName converts the snake case `quotaName` to an alert name
+// such as AWSSpotInstanceRequestsHighUtilization
+func alertName(quotaName, suffix string) string {
+	var name strings.Builder
+	name.WriteString("AWS")
+	for _, part := range strings.Split(quotaName, "_") {
+		if part == "" {
+			continue
+		}
+		name.WriteString(strings.ToUpper(part[:1]))
+		name.WriteString(part[1:])
+	}
+	name.WriteString(suffix)
+	return name.String()
+}
+
+func promDuration(d time.Duration) string {
+	return model.Duration(d).String()
+}
+
+// limitedDefinitions returns the definitions in `definitions` that
+// have a limit, leaving out the breakdowns of other quotas
+func limitedDefinitions(definitions []servicequotas.QuotaDefinition) []servicequotas.QuotaDefinition {
+	limited := []servicequotas.QuotaDefinition{}
+	for _, definition := range definitions {
+		if !definition.Breakdown {
+			limited = append(limited, definition)
+		}
+	}
+	return limited
+}
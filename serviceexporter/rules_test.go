@@ -0,0 +1,111 @@
+package serviceexporter
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/thought-machine/aws-service-quotas-exporter/servicequotas"
+)
+
+func TestAlertName(t *testing.T) {
+	assert.Equal(t, "AWSSpotInstanceRequestsHighUtilization", alertName("spot_instance_requests", "HighUtilization"))
+	assert.Equal(t, "AWSAvailableIpsPerSubnetForecastExhaustion", alertName("available_ips_per_subnet", "ForecastExhaustion"))
+}
+
+func TestWriteAlertingRules(t *testing.T) {
+	definitions := []servicequotas.QuotaDefinition{
+		{Name: "spot_instance_requests", Description: "spot instance requests"},
+	}
+	config := RulesConfig{
+		WarningThreshold:  0.8,
+		CriticalThreshold: 0.95,
+		For:               15 * time.Minute,
+		ForecastLookback:  6 * time.Hour,
+		ForecastWindow:    24 * time.Hour,
+	}
+
+	testCases := []struct {
+		name               string
+		prometheusRuleName string
+		expectedHeader     string
+	}{
+		{
+			name:           "AsRulesFile",
+			expectedHeader: "groups:\n",
+		},
+		{
+			name:               "AsPrometheusRule",
+			prometheusRuleName: "aws-service-quotas",
+			expectedHeader:     "apiVersion: monitoring.coreos.com/v1\nkind: PrometheusRule\nmetadata:\n  name: aws-service-quotas\nspec:\n  groups:\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config.PrometheusRuleName = tc.prometheusRuleName
+
+			var out bytes.Buffer
+			err := WriteAlertingRules(&out, definitions, config)
+
+			assert.NoError(t, err)
+			assert.True(t, bytes.HasPrefix(out.Bytes(), []byte(tc.expectedHeader)))
+		})
+	}
+}
+
+func TestWriteAlertingRulesExpressions(t *testing.T) {
+	definitions := []servicequotas.QuotaDefinition{
+		{Name: "spot_instance_requests", Description: "spot instance requests"},
+		{Name: "spot_instance_requests_breakdown", Description: "spot instance requests by instance family", Breakdown: true},
+	}
+	config := RulesConfig{
+		WarningThreshold:  0.8,
+		CriticalThreshold: 0.95,
+		For:               15 * time.Minute,
+		ForecastLookback:  6 * time.Hour,
+		ForecastWindow:    24 * time.Hour,
+	}
+
+	var out bytes.Buffer
+	err := WriteAlertingRules(&out, definitions, config)
+	assert.NoError(t, err)
+
+	var groups ruleGroups
+	assert.NoError(t, yaml.Unmarshal(out.Bytes(), &groups))
+
+	expectedGroups := ruleGroups{
+		Groups: []ruleGroup{
+			{
+				Name: rulesGroupName,
+				Rules: []alertingRule{
+					{
+						Alert:       "AWSSpotInstanceRequestsCriticalUtilization",
+						For:         "15m",
+						Expr:        "aws_spot_instance_requests_used_total / (aws_spot_instance_requests_limit_total > 0) >= 0.95",
+						Labels:      map[string]string{"severity": "critical", "quota": "spot_instance_requests"},
+						Annotations: map[string]string{"summary": "{{ $labels.resource }} in {{ $labels.region }} is using {{ $value | humanizePercentage }} of its spot instance requests limit"},
+					},
+					{
+						Alert:       "AWSSpotInstanceRequestsHighUtilization",
+						For:         "15m",
+						Expr:        "aws_spot_instance_requests_used_total / (aws_spot_instance_requests_limit_total > 0) >= 0.8",
+						Labels:      map[string]string{"severity": "warning", "quota": "spot_instance_requests"},
+						Annotations: map[string]string{"summary": "{{ $labels.resource }} in {{ $labels.region }} is using {{ $value | humanizePercentage }} of its spot instance requests limit"},
+					},
+					{
+						Alert:       "AWSSpotInstanceRequestsForecastExhaustion",
+						For:         "15m",
+						Expr:        "predict_linear(aws_spot_instance_requests_used_total[6h], 86400) >= (aws_spot_instance_requests_limit_total > 0)",
+						Labels:      map[string]string{"severity": "warning", "quota": "spot_instance_requests"},
+						Annotations: map[string]string{"summary": "{{ $labels.resource }} in {{ $labels.region }} is forecast to reach its spot instance requests limit within 1d"},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expectedGroups, groups)
+}
@@ -4,6 +4,8 @@ package serviceexporter
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,6 +16,11 @@ import (
 
 var log = logging.WithFields(logging.Fields{})
 
+const (
+	usedMetricName  = "used_total"
+	limitMetricName = "limit_total"
+)
+
 // Metric holds usage and limit desc and values
 type Metric struct {
 	usageDesc   *prometheus.Desc
@@ -27,6 +34,55 @@ func metricKey(quota servicequotas.QuotaUsage) string {
 	return fmt.Sprintf("%s%s", quota.Name, quota.Identifier())
 }
 
+// checkStatus holds the latest state of a usage check. The usages
+// are kept from the last successful run when the check fails
+type checkStatus struct {
+	definitions []servicequotas.QuotaDefinition
+	usages      []servicequotas.QuotaUsage
+	lastRefresh time.Time
+	err         error
+}
+
+func checkKey(definitions []servicequotas.QuotaDefinition) string {
+	names := make([]string, 0, len(definitions))
+	for _, definition := range definitions {
+		names = append(names, definition.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+// quotaLabels returns the label names and values of the metrics
+// exported for `quota`
+func quotaLabels(quota servicequotas.QuotaUsage, includedAWSTags []string) ([]string, []string) {
+	labels := []string{"resource"}
+	labelValues := []string{quota.Identifier()}
+
+	for _, label := range quotaLabelNames(quota) {
+		labels = append(labels, label)
+		labelValues = append(labelValues, quota.Labels[label])
+	}
+
+	for _, tag := range includedAWSTags {
+		prometheusFormatTag := servicequotas.ToPrometheusNamingFormat(tag)
+		labels = append(labels, prometheusFormatTag)
+		// Need to set empty label value to keep label name and value count the same
+		labelValues = append(labelValues, quota.Tags[prometheusFormatTag])
+	}
+
+	return labels, labelValues
+}
+
+// quotaLabelNames returns the names of the additional labels of
+// `quota` sorted so they are exported in a stable order
+func quotaLabelNames(quota servicequotas.QuotaUsage) []string {
+	names := make([]string, 0, len(quota.Labels))
+	for name := range quota.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // ServiceQuotasExporter AWS service quotas and usage prometheus
 // exporter
 type ServiceQuotasExporter struct {
@@ -37,11 +93,21 @@ type ServiceQuotasExporter struct {
 	refreshPeriod   int
 	waitForMetrics  chan struct{}
 	includedAWSTags []string
+	checkStatuses   map[string]checkStatus
+	// failedDescs holds the descriptors of the quotas whose check
+	// failed on the first run, keyed by quota name, so their
+	// metrics can still be created once the check recovers
+	failedDescs map[string][]*prometheus.Desc
+	lastRefresh time.Time
+	refreshErr  error
+	sinks       []Sink
 }
 
-// NewServiceQuotasExporter creates a new ServiceQuotasExporter
-func NewServiceQuotasExporter(region, profile string, refreshPeriod int, includedAWSTags []string) (*ServiceQuotasExporter, error) {
-	quotasClient, err := servicequotas.NewServiceQuotas(region, profile)
+// NewServiceQuotasExporter creates a new ServiceQuotasExporter with
+// the usage checks configured by `quotasOptions`. The quotas and
+// usage are sent to `sinks` after every refresh
+func NewServiceQuotasExporter(region, profile string, refreshPeriod int, includedAWSTags []string, quotasOptions servicequotas.Options, sinks ...Sink) (*ServiceQuotasExporter, error) {
+	quotasClient, err := servicequotas.NewServiceQuotasWithOptions(region, profile, quotasOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -55,6 +121,7 @@ func NewServiceQuotasExporter(region, profile string, refreshPeriod int, include
 		refreshPeriod:   refreshPeriod,
 		waitForMetrics:  ch,
 		includedAWSTags: includedAWSTags,
+		sinks:           sinks,
 	}
 	go exporter.createOrUpdateQuotasAndDescriptions(false)
 	go exporter.refreshMetrics()
@@ -72,50 +139,66 @@ func (e *ServiceQuotasExporter) refreshMetrics() {
 }
 
 func (e *ServiceQuotasExporter) createOrUpdateQuotasAndDescriptions(update bool) {
-	quotas, err := e.quotasClient.QuotasAndUsage()
+	results, err := e.quotasClient.CheckResults()
 	if err != nil {
-		log.Fatalf("Could not retrieve quotas and limits: %s", err)
+		if !update {
+			log.Fatalf("Could not retrieve quotas and limits: %s", err)
+		}
+
+		log.Errorf("Could not refresh quotas and limits: %s", err)
+		e.metricsLock.Lock()
+		e.refreshErr = err
+		e.metricsLock.Unlock()
+		return
 	}
 
+	quotas := []servicequotas.QuotaUsage{}
+	failedDefinitions := []servicequotas.QuotaDefinition{}
+	for _, result := range results {
+		if result.Err != nil {
+			log.Errorf("Could not refresh quotas and limits of %s: %s", checkKey(result.Definitions), result.Err)
+			failedDefinitions = append(failedDefinitions, result.Definitions...)
+			continue
+		}
+		quotas = append(quotas, result.Usages...)
+	}
+
+	defer e.sendToSinks(quotas)
+
 	e.metricsLock.Lock()
 	defer e.metricsLock.Unlock()
 
+	e.updateCheckStatuses(results)
+
+	if !update {
+		e.describeFailedDefinitions(failedDefinitions)
+	}
+
 	for _, quota := range quotas {
 		key := metricKey(quota)
 		resourceID := quota.Identifier()
+		labels, labelValues := quotaLabels(quota, e.includedAWSTags)
 
-		labels := []string{"resource"}
-		labelValues := []string{resourceID}
-
-		for _, tag := range e.includedAWSTags {
-			prometheusFormatTag := servicequotas.ToPrometheusNamingFormat(tag)
-			labels = append(labels, prometheusFormatTag)
-			// Need to set empty label value to keep label name and value count the same
-			labelValues = append(labelValues, quota.Tags[prometheusFormatTag])
-		}
-
-		if update {
-			if resourceMetric, ok := e.metrics[key]; ok {
-				log.Infof("Updating metrics for resource (%s)", resourceID)
-				resourceMetric.usage = quota.Usage
-				resourceMetric.limit = quota.Quota
-				resourceMetric.labelValues = labelValues
-				e.metrics[key] = resourceMetric
-			}
-		} else {
-			usageHelp := fmt.Sprintf("Used amount of %s", quota.Description)
-			usageDesc := newDesc(e.metricsRegion, quota.Name, "used_total", usageHelp, labels)
-
-			limitHelp := fmt.Sprintf("Limit of %s", quota.Description)
-			limitDesc := newDesc(e.metricsRegion, quota.Name, "limit_total", limitHelp, labels)
-			resourceMetric := Metric{
+		resourceMetric, ok := e.metrics[key]
+		switch {
+		case update && ok:
+			log.Infof("Updating metrics for resource (%s)", resourceID)
+			resourceMetric.usage = quota.Usage
+			resourceMetric.limit = quota.Quota
+			resourceMetric.labelValues = labelValues
+			e.metrics[key] = resourceMetric
+		case update && e.failedDescs[quota.Name] == nil:
+			// Metric descriptions can only be created on the first
+			// run or for the checks that failed on it
+		default:
+			usageDesc, limitDesc := quotaDescs(e.metricsRegion, quota, labels)
+			e.metrics[key] = Metric{
 				usageDesc:   usageDesc,
 				limitDesc:   limitDesc,
 				usage:       quota.Usage,
 				limit:       quota.Quota,
 				labelValues: labelValues,
 			}
-			e.metrics[key] = resourceMetric
 		}
 	}
 
@@ -124,6 +207,51 @@ func (e *ServiceQuotasExporter) createOrUpdateQuotasAndDescriptions(update bool)
 	}
 }
 
+// describeFailedDefinitions creates the descriptors of the quotas in
+// `definitions`, whose check failed on the first run. Prometheus only
+// checks the name and constant labels of collected metrics against
+// the registered descriptors, so the metrics created with the actual
+// labels once the check recovers can be collected. Must be called
+// with the metrics lock held
+func (e *ServiceQuotasExporter) describeFailedDefinitions(definitions []servicequotas.QuotaDefinition) {
+	e.failedDescs = make(map[string][]*prometheus.Desc, len(definitions))
+	for _, definition := range definitions {
+		quota := servicequotas.QuotaUsage{Name: definition.Name, Description: definition.Description}
+		labels, _ := quotaLabels(quota, e.includedAWSTags)
+		usageDesc, limitDesc := quotaDescs(e.metricsRegion, quota, labels)
+		e.failedDescs[definition.Name] = []*prometheus.Desc{usageDesc, limitDesc}
+	}
+}
+
+func (e *ServiceQuotasExporter) sendToSinks(quotas []servicequotas.QuotaUsage) {
+	for _, sink := range e.sinks {
+		if err := sink.Send(quotas); err != nil {
+			log.Errorf("Failed to send quotas to sink: %s", err)
+		}
+	}
+}
+
+// updateCheckStatuses updates the status of every usage check from
+// `results`. Must be called with the metrics lock held
+func (e *ServiceQuotasExporter) updateCheckStatuses(results []servicequotas.CheckResult) {
+	statuses := make(map[string]checkStatus, len(results))
+	for _, result := range results {
+		key := checkKey(result.Definitions)
+		status := e.checkStatuses[key]
+		status.definitions = result.Definitions
+		status.err = result.Err
+		if result.Err == nil {
+			status.usages = result.Usages
+			status.lastRefresh = result.Time
+		}
+		statuses[key] = status
+	}
+
+	e.checkStatuses = statuses
+	e.lastRefresh = time.Now()
+	e.refreshErr = nil
+}
+
 // Describe writes descriptors to the prometheus desc channel
 func (e *ServiceQuotasExporter) Describe(ch chan<- *prometheus.Desc) {
 	<-e.waitForMetrics
@@ -132,6 +260,11 @@ func (e *ServiceQuotasExporter) Describe(ch chan<- *prometheus.Desc) {
 		ch <- metric.usageDesc
 		ch <- metric.limitDesc
 	}
+	for _, descs := range e.failedDescs {
+		for _, desc := range descs {
+			ch <- desc
+		}
+	}
 }
 
 // Collect implements the collect function for prometheus collectors
@@ -145,9 +278,27 @@ func (e *ServiceQuotasExporter) Collect(ch chan<- prometheus.Metric) {
 	}
 }
 
+// fqName returns the fully qualified name of the metric exported for
+// `quotaName`
+func fqName(quotaName, metricName string) string {
+	return prometheus.BuildFQName("aws", quotaName, metricName)
+}
+
+// quotaDescs returns the usage and limit descriptors of the metrics
+// exported for `quota`
+func quotaDescs(region string, quota servicequotas.QuotaUsage, labels []string) (*prometheus.Desc, *prometheus.Desc) {
+	usageHelp := fmt.Sprintf("Used amount of %s", quota.Description)
+	usageDesc := newDesc(region, quota.Name, usedMetricName, usageHelp, labels)
+
+	limitHelp := fmt.Sprintf("Limit of %s", quota.Description)
+	limitDesc := newDesc(region, quota.Name, limitMetricName, limitHelp, labels)
+
+	return usageDesc, limitDesc
+}
+
 func newDesc(region, quotaName, metricName, help string, labels []string) *prometheus.Desc {
 	return prometheus.NewDesc(
-		prometheus.BuildFQName("aws", quotaName, metricName),
+		fqName(quotaName, metricName),
 		help,
 		labels,
 		prometheus.Labels{"region": region},
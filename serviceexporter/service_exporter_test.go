@@ -1,9 +1,12 @@
 package serviceexporter
 
 import (
+	"errors"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/thought-machine/aws-service-quotas-exporter/servicequotas"
@@ -14,14 +17,33 @@ func resourceName(name string) *string {
 }
 
 type ServiceQuotasMock struct {
-	quotas []servicequotas.QuotaUsage
-	err    error
+	quotas      []servicequotas.QuotaUsage
+	definitions []servicequotas.QuotaDefinition
+	results     []servicequotas.CheckResult
+	err         error
+	timesCalled int
 }
 
 func (s *ServiceQuotasMock) QuotasAndUsage() ([]servicequotas.QuotaUsage, error) {
+	s.timesCalled++
 	return s.quotas, s.err
 }
 
+func (s *ServiceQuotasMock) CheckResults() ([]servicequotas.CheckResult, error) {
+	s.timesCalled++
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.results != nil {
+		return s.results, nil
+	}
+	return []servicequotas.CheckResult{{Definitions: s.definitions, Usages: s.quotas}}, nil
+}
+
+func (s *ServiceQuotasMock) Definitions() []servicequotas.QuotaDefinition {
+	return s.definitions
+}
+
 func TestUpdateMetrics(t *testing.T) {
 	quotasClient := &ServiceQuotasMock{
 		quotas: []servicequotas.QuotaUsage{
@@ -155,3 +177,112 @@ func TestCreateQuotasAndDescriptionsRefresh(t *testing.T) {
 
 	close(ch) // should panic if it was already closed
 }
+
+func TestUpdateMetricsWithErrors(t *testing.T) {
+	quotasClient := &ServiceQuotasMock{err: errors.New("some err")}
+
+	exporter := &ServiceQuotasExporter{
+		metricsRegion: "eu-west-1",
+		quotasClient:  quotasClient,
+		metrics: map[string]Metric{
+			"i-asdasd1": Metric{usage: 3, limit: 5, labelValues: []string{"i-asdasd1"}},
+		},
+		metricsLock:   &sync.Mutex{},
+		refreshPeriod: 360,
+	}
+
+	exporter.createOrUpdateQuotasAndDescriptions(true)
+
+	expectedMetrics := map[string]Metric{
+		"i-asdasd1": Metric{usage: 3, limit: 5, labelValues: []string{"i-asdasd1"}},
+	}
+	exporter.metricsLock.Lock()
+	defer exporter.metricsLock.Unlock()
+	assert.Equal(t, expectedMetrics, exporter.metrics)
+	assert.Equal(t, quotasClient.err, exporter.refreshErr)
+}
+
+func TestCreateQuotasAndDescriptionsWithFailedCheck(t *testing.T) {
+	working := servicequotas.QuotaUsage{Name: "working", ResourceName: resourceName("i-1"), Description: "working", Usage: 1, Quota: 2}
+	failing := servicequotas.QuotaUsage{Name: "failing", ResourceName: resourceName("vpc-1"), Description: "failing", Usage: 3, Quota: 4, Labels: map[string]string{"vpc_id": "vpc-1"}}
+	quotasClient := &ServiceQuotasMock{
+		results: []servicequotas.CheckResult{
+			{Definitions: []servicequotas.QuotaDefinition{{Name: "working", Description: "working"}}, Usages: []servicequotas.QuotaUsage{working}},
+			{Definitions: []servicequotas.QuotaDefinition{{Name: "failing", Description: "failing"}}, Err: errors.New("some err")},
+		},
+	}
+
+	exporter := &ServiceQuotasExporter{
+		metricsRegion:  "eu-west-1",
+		quotasClient:   quotasClient,
+		metrics:        map[string]Metric{},
+		metricsLock:    &sync.Mutex{},
+		refreshPeriod:  360,
+		waitForMetrics: make(chan struct{}),
+	}
+
+	exporter.createOrUpdateQuotasAndDescriptions(false)
+
+	assert.Len(t, exporter.metrics, 1)
+	assert.Contains(t, exporter.metrics, "workingi-1")
+	assert.EqualError(t, exporter.checkStatuses["failing"].err, "some err")
+
+	registry := prometheus.NewPedanticRegistry()
+	assert.NoError(t, registry.Register(exporter))
+
+	quotasClient.results[1] = servicequotas.CheckResult{
+		Definitions: []servicequotas.QuotaDefinition{{Name: "failing", Description: "failing"}},
+		Usages:      []servicequotas.QuotaUsage{failing},
+	}
+	exporter.createOrUpdateQuotasAndDescriptions(true)
+
+	assert.Equal(t, 3.0, exporter.metrics["failingvpc-1"].usage)
+	assert.Equal(t, 4.0, exporter.metrics["failingvpc-1"].limit)
+
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+	assert.Len(t, families, 4)
+}
+
+func TestUpdateCheckStatuses(t *testing.T) {
+	refreshed := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	definitions := []servicequotas.QuotaDefinition{{Name: "first"}, {Name: "second"}}
+	previousUsages := []servicequotas.QuotaUsage{{Name: "first", Usage: 1}}
+
+	exporter := &ServiceQuotasExporter{
+		metricsLock: &sync.Mutex{},
+		checkStatuses: map[string]checkStatus{
+			"first,second": {definitions: definitions, usages: previousUsages, lastRefresh: refreshed},
+			"removed":      {},
+		},
+		refreshErr: errors.New("previous err"),
+	}
+
+	checkErr := errors.New("some err")
+	otherUsages := []servicequotas.QuotaUsage{{Name: "other", Usage: 2}}
+	exporter.updateCheckStatuses([]servicequotas.CheckResult{
+		{Definitions: definitions, Err: checkErr, Time: refreshed.Add(time.Hour)},
+		{Definitions: []servicequotas.QuotaDefinition{{Name: "other"}}, Usages: otherUsages, Time: refreshed.Add(time.Hour)},
+	})
+
+	expectedStatuses := map[string]checkStatus{
+		"first,second": {definitions: definitions, usages: previousUsages, lastRefresh: refreshed, err: checkErr},
+		"other":        {definitions: []servicequotas.QuotaDefinition{{Name: "other"}}, usages: otherUsages, lastRefresh: refreshed.Add(time.Hour)},
+	}
+	assert.Equal(t, expectedStatuses, exporter.checkStatuses)
+	assert.NoError(t, exporter.refreshErr)
+}
+
+func TestQuotaLabels(t *testing.T) {
+	quota := servicequotas.QuotaUsage{
+		Name:         "available_ips_per_availability_zone",
+		ResourceName: resourceName("vpc-1/eu-west-1a"),
+		Tags:         map[string]string{"team": "networking"},
+		Labels:       map[string]string{"vpc_id": "vpc-1", "availability_zone": "eu-west-1a"},
+	}
+
+	labels, labelValues := quotaLabels(quota, []string{"team"})
+
+	assert.Equal(t, []string{"resource", "availability_zone", "vpc_id", "team"}, labels)
+	assert.Equal(t, []string{"vpc-1/eu-west-1a", "eu-west-1a", "vpc-1", "networking"}, labelValues)
+}
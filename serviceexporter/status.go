@@ -0,0 +1,148 @@
+package serviceexporter
+
+import (
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	statusWarningThreshold  = 0.8
+	statusCriticalThreshold = 0.95
+)
+
+var statusTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>AWS service quotas - {{ .Region }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+td.number { text-align: right; }
+tr.ok { background: #e6f4ea; }
+tr.warning { background: #fef7e0; }
+tr.critical { background: #fce8e6; }
+.error { color: #c5221f; }
+</style>
+</head>
+<body>
+<h1>AWS service quotas - {{ .Region }}</h1>
+<p>Last refresh: {{ .LastRefresh }}</p>
+{{ if .Errors }}
+<h2>Errors</h2>
+<ul>
+{{ range .Errors }}<li class="error">{{ if .Quotas }}{{ .Quotas }}: {{ end }}{{ .Error }}</li>
+{{ end }}</ul>
+{{ end }}
+<h2>Quotas</h2>
+<table>
+<tr><th>Quota</th><th>Resource</th><th>Description</th><th>Usage</th><th>Limit</th><th>Utilization</th><th>Last refresh</th></tr>
+{{ range .Quotas }}<tr class="{{ .Class }}"><td>{{ .Name }}</td><td>{{ .Resource }}</td><td>{{ .Description }}</td><td class="number">{{ .Usage }}</td><td class="number">{{ .Limit }}</td><td class="number">{{ printf "%.1f" .Utilization }}%</td><td>{{ .LastRefresh }}</td></tr>
+{{ end }}</table>
+</body>
+</html>
+`))
+
+type statusPageQuota struct {
+	Name        string
+	Resource    string
+	Description string
+	Usage       float64
+	Limit       float64
+	Utilization float64
+	Class       string
+	LastRefresh string
+}
+
+type statusPageError struct {
+	Quotas string
+	Error  string
+}
+
+type statusPage struct {
+	Region      string
+	LastRefresh string
+	Errors      []statusPageError
+	Quotas      []statusPageQuota
+}
+
+// utilizationClass returns the CSS class colour coding `utilization`
+func utilizationClass(utilization float64) string {
+	switch {
+	case utilization >= statusCriticalThreshold:
+		return "critical"
+	case utilization >= statusWarningThreshold:
+		return "warning"
+	default:
+		return "ok"
+	}
+}
+
+func formatRefreshTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// StatusPageHandler serves an HTML page listing all quotas sorted by
+// utilization together with the errors of failing usage checks
+type StatusPageHandler struct {
+	exporter *ServiceQuotasExporter
+}
+
+// NewStatusPageHandler creates a StatusPageHandler serving the state
+// of `exporter`
+func NewStatusPageHandler(exporter *ServiceQuotasExporter) *StatusPageHandler {
+	return &StatusPageHandler{exporter: exporter}
+}
+
+// ServeHTTP renders the status page
+func (h *StatusPageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	current := h.exporter.snapshot()
+	page := statusPage{
+		Region:      current.region,
+		LastRefresh: formatRefreshTime(current.lastRefresh),
+	}
+
+	if current.refreshErr != nil {
+		page.Errors = append(page.Errors, statusPageError{Error: current.refreshErr.Error()})
+	}
+
+	for _, check := range current.checks {
+		if check.err != nil {
+			names := []string{}
+			for _, definition := range check.definitions {
+				names = append(names, definition.Name)
+			}
+			page.Errors = append(page.Errors, statusPageError{Quotas: strings.Join(names, ", "), Error: check.err.Error()})
+		}
+
+		for _, quota := range check.usages {
+			quotaUtilization := utilization(quota)
+			page.Quotas = append(page.Quotas, statusPageQuota{
+				Name:        quota.Name,
+				Resource:    quota.Identifier(),
+				Description: quota.Description,
+				Usage:       quota.Usage,
+				Limit:       quota.Quota,
+				Utilization: quotaUtilization * 100,
+				Class:       utilizationClass(quotaUtilization),
+				LastRefresh: formatRefreshTime(check.lastRefresh),
+			})
+		}
+	}
+
+	sort.SliceStable(page.Quotas, func(i, j int) bool {
+		return page.Quotas[i].Utilization > page.Quotas[j].Utilization
+	})
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := statusTemplate.Execute(w, page); err != nil {
+		log.Errorf("Failed to render status page: %s", err)
+	}
+}
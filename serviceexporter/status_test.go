@@ -0,0 +1,35 @@
+package serviceexporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUtilizationClass(t *testing.T) {
+	assert.Equal(t, "ok", utilizationClass(0))
+	assert.Equal(t, "ok", utilizationClass(0.79))
+	assert.Equal(t, "warning", utilizationClass(0.8))
+	assert.Equal(t, "critical", utilizationClass(0.95))
+	assert.Equal(t, "critical", utilizationClass(1.2))
+}
+
+func TestStatusPage(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	NewStatusPageHandler(newTestAPIExporter()).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	body := recorder.Body.String()
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, body, "Last refresh: 2023-05-01T12:00:00Z")
+	assert.Contains(t, body, `<li class="error">spot: some err</li>`)
+	assert.Contains(t, body, `<tr class="warning"><td>rules</td><td>sg-1</td><td></td><td class="number">45</td><td class="number">50</td><td class="number">90.0%</td><td>2023-05-01T12:00:00Z</td></tr>`)
+	assert.Contains(t, body, `<tr class="ok"><td>spot</td><td>spot</td><td></td><td class="number">0</td><td class="number">0</td><td class="number">0.0%</td><td>2023-05-01T11:00:00Z</td></tr>`)
+
+	// quotas are sorted by utilization
+	assert.Less(t, strings.Index(body, "sg-1"), strings.Index(body, "sg-2"))
+	assert.Less(t, strings.Index(body, "sg-2"), strings.Index(body, "<td>spot</td>"))
+}
@@ -0,0 +1,41 @@
+package servicequotas
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+)
+
+// accountIDCache caches the ID of the account of the credentials used
+// by the usage checks, which does not change, so usage checks
+// excluding resources shared from other accounts only retrieve it once
+type accountIDCache struct {
+	client    stsiface.STSAPI
+	lock      sync.Mutex
+	accountID string
+}
+
+func newAccountIDCache(client stsiface.STSAPI) *accountIDCache {
+	return &accountIDCache{client: client}
+}
+
+// AccountID returns the ID of the account of the credentials,
+// retrieving it on first use, or an error
+func (c *accountIDCache) AccountID() (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.accountID != "" {
+		return c.accountID, nil
+	}
+
+	identity, err := c.client.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", err
+	}
+
+	c.accountID = aws.StringValue(identity.Account)
+	return c.accountID, nil
+}
@@ -0,0 +1,30 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountIDCacheWithError(t *testing.T) {
+	mockClient := &mockSTSClient{err: errors.New("some err")}
+
+	accountID, err := newAccountIDCache(mockClient).AccountID()
+
+	assert.Error(t, err)
+	assert.Empty(t, accountID)
+}
+
+func TestAccountIDCache(t *testing.T) {
+	mockClient := &mockSTSClient{accountID: "123456789012"}
+	cache := newAccountIDCache(mockClient)
+
+	for i := 0; i < 3; i++ {
+		accountID, err := cache.AccountID()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "123456789012", accountID)
+	}
+	assert.Equal(t, 1, mockClient.GetCallerIdentityCalls)
+}
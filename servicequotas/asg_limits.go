@@ -57,6 +57,11 @@ func (c *ASGUsageCheck) Usage() ([]QuotaUsage, error) {
 	return quotaUsages, nil
 }
 
+// Definitions returns the instances per ASG information
+func (c *ASGUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: numInstancesPerASGName, Description: numInstancesPerASGDescription}}
+}
+
 func isRunning(instance *autoscaling.Instance) bool {
 	notRunningStates := map[string]bool{
 		"Terminating":         true,
@@ -0,0 +1,143 @@
+package servicequotas
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	awsservicequotas "github.com/aws/aws-sdk-go/service/servicequotas"
+	logging "github.com/sirupsen/logrus"
+)
+
+const (
+	dedicatedHostsPerFamilyName = "dedicated_hosts_per_instance_family"
+	dedicatedHostsPerFamilyDesc = "running dedicated hosts per instance family"
+)
+
+// dedicatedHostsQuotaName matches the names of the dedicated hosts
+// quotas, of which there is one per instance family
+var dedicatedHostsQuotaName = regexp.MustCompile(`^Running Dedicated (\S+) Hosts$`)
+
+// releasedHostStates are the states of dedicated hosts that no longer
+// count against the dedicated hosts quotas
+var releasedHostStates = map[string]bool{
+	ec2.AllocationStateReleased:                 true,
+	ec2.AllocationStateReleasedPermanentFailure: true,
+}
+
+// DedicatedHostsUsageCheck implements the UsageCheck interface for
+// running dedicated hosts per instance family. There is a quota per
+// instance family, so the quotas are matched by name rather than by
+// quota code from the ec2 quotas listed by ServiceQuotas
+type DedicatedHostsUsageCheck struct {
+	client ec2iface.EC2API
+	lock   sync.Mutex
+	quotas map[string]float64
+}
+
+// Usage returns usage for each instance family with dedicated hosts
+// with the usage value being the number of dedicated hosts allocated
+// for the family and the quota its running dedicated hosts quota or
+// an error
+func (c *DedicatedHostsUsageCheck) Usage() ([]QuotaUsage, error) {
+	hostsPerFamily := map[string]int{}
+
+	params := &ec2.DescribeHostsInput{}
+	err := c.client.DescribeHostsPages(params,
+		func(page *ec2.DescribeHostsOutput, lastPage bool) bool {
+			if page != nil {
+				for _, host := range page.Hosts {
+					if releasedHostStates[aws.StringValue(host.State)] || host.HostProperties == nil {
+						continue
+					}
+					if family := hostInstanceFamily(host.HostProperties); family != "" {
+						hostsPerFamily[family]++
+					}
+				}
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	if len(hostsPerFamily) == 0 {
+		return []QuotaUsage{}, nil
+	}
+
+	c.lock.Lock()
+	quotas := c.quotas
+	c.lock.Unlock()
+	if quotas == nil {
+		return nil, fmt.Errorf("%w: running dedicated hosts quotas have not been listed", ErrFailedToGetUsage)
+	}
+
+	families := make([]string, 0, len(hostsPerFamily))
+	for family := range hostsPerFamily {
+		families = append(families, family)
+	}
+	sort.Strings(families)
+
+	quotaUsages := []QuotaUsage{}
+	for _, family := range families {
+		quota, ok := quotas[family]
+		if !ok {
+			logging.Warnf("No dedicated hosts quota for instance family (%s), skipping its dedicated hosts", family)
+			continue
+		}
+
+		resourceName := family
+		quotaUsages = append(quotaUsages, QuotaUsage{
+			Name:         dedicatedHostsPerFamilyName,
+			ResourceName: &resourceName,
+			Description:  dedicatedHostsPerFamilyDesc,
+			Usage:        float64(hostsPerFamily[family]),
+			Quota:        quota,
+			Labels:       map[string]string{instanceFamilyLabel: family},
+		})
+	}
+
+	return quotaUsages, nil
+}
+
+func (c *DedicatedHostsUsageCheck) serviceCode() string {
+	return "ec2"
+}
+
+// setQuotas sets the running dedicated hosts quotas by instance family
+// from the ec2 `quotas`
+func (c *DedicatedHostsUsageCheck) setQuotas(quotas []*awsservicequotas.ServiceQuota) {
+	quotasPerFamily := map[string]float64{}
+	for _, quota := range quotas {
+		match := dedicatedHostsQuotaName.FindStringSubmatch(aws.StringValue(quota.QuotaName))
+		if match != nil && quota.Value != nil {
+			quotasPerFamily[strings.ToLower(match[1])] = *quota.Value
+		}
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.quotas = quotasPerFamily
+}
+
+// Definitions returns the running dedicated hosts per instance family
+// quota
+func (c *DedicatedHostsUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: dedicatedHostsPerFamilyName, Description: dedicatedHostsPerFamilyDesc}}
+}
+
+// hostInstanceFamily returns the instance family of a dedicated host,
+// which is only set on hosts supporting multiple instance types
+func hostInstanceFamily(properties *ec2.HostProperties) string {
+	if properties.InstanceFamily != nil {
+		return strings.ToLower(*properties.InstanceFamily)
+	}
+	family, _, _ := strings.Cut(aws.StringValue(properties.InstanceType), ".")
+	return strings.ToLower(family)
+}
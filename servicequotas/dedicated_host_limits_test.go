@@ -0,0 +1,107 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	awsservicequotas "github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/stretchr/testify/assert"
+)
+
+func (m *mockEC2Client) DescribeHostsPages(input *ec2.DescribeHostsInput, fn func(*ec2.DescribeHostsOutput, bool) bool) error {
+	fn(m.DescribeHostsResponse, true)
+	return m.err
+}
+
+func TestDedicatedHostsUsageWithError(t *testing.T) {
+	testCases := []struct {
+		name   string
+		client *mockEC2Client
+	}{
+		{
+			name:   "WithHostsError",
+			client: &mockEC2Client{err: errors.New("some err")},
+		},
+		{
+			name: "WithoutListedQuotas",
+			client: &mockEC2Client{
+				DescribeHostsResponse: &ec2.DescribeHostsOutput{
+					Hosts: []*ec2.Host{
+						{HostId: aws.String("h-1"), HostProperties: &ec2.HostProperties{InstanceType: aws.String("m5.large")}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := DedicatedHostsUsageCheck{client: tc.client}
+			usage, err := check.Usage()
+
+			assert.Error(t, err)
+			assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+			assert.Nil(t, usage)
+		})
+	}
+}
+
+func TestDedicatedHostsUsage(t *testing.T) {
+	host := func(state string, properties *ec2.HostProperties) *ec2.Host {
+		return &ec2.Host{State: aws.String(state), HostProperties: properties}
+	}
+
+	mockClient := &mockEC2Client{
+		DescribeHostsResponse: &ec2.DescribeHostsOutput{
+			Hosts: []*ec2.Host{
+				host("available", &ec2.HostProperties{InstanceType: aws.String("m5.large")}),
+				host("available", &ec2.HostProperties{InstanceFamily: aws.String("m5")}),
+				host("under-assessment", &ec2.HostProperties{InstanceType: aws.String("c5.xlarge")}),
+				host("released", &ec2.HostProperties{InstanceType: aws.String("c5.xlarge")}),
+				host("available", &ec2.HostProperties{InstanceType: aws.String("x9.large")}),
+			},
+		},
+	}
+
+	check := DedicatedHostsUsageCheck{client: mockClient}
+	check.setQuotas([]*awsservicequotas.ServiceQuota{
+		{QuotaName: aws.String("Running Dedicated m5 Hosts"), Value: aws.Float64(2)},
+		{QuotaName: aws.String("Running Dedicated c5 Hosts"), Value: aws.Float64(4)},
+		{QuotaName: aws.String("Running On-Demand Standard (A, C, D, H, I, M, R, T, Z) instances"), Value: aws.Float64(256)},
+	})
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         dedicatedHostsPerFamilyName,
+			ResourceName: aws.String("c5"),
+			Description:  dedicatedHostsPerFamilyDesc,
+			Usage:        1,
+			Quota:        4,
+			Labels:       map[string]string{"instance_family": "c5"},
+		},
+		{
+			Name:         dedicatedHostsPerFamilyName,
+			ResourceName: aws.String("m5"),
+			Description:  dedicatedHostsPerFamilyDesc,
+			Usage:        2,
+			Quota:        2,
+			Labels:       map[string]string{"instance_family": "m5"},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
+
+func TestDedicatedHostsUsageWithoutHosts(t *testing.T) {
+	check := DedicatedHostsUsageCheck{
+		client: &mockEC2Client{DescribeHostsResponse: &ec2.DescribeHostsOutput{}},
+	}
+	usage, err := check.Usage()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []QuotaUsage{}, usage)
+}
@@ -0,0 +1,224 @@
+package servicequotas
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// The EBS quotas are reported under "ebs", but all of the usage checks
+// are using the ec2 service
+const (
+	ebsSnapshotsPerRegionName = "ebs_snapshots_per_region"
+	ebsSnapshotsPerRegionDesc = "EBS snapshots per region"
+
+	gibPerTiB = 1024
+
+	selfSnapshotOwner = "self"
+)
+
+// ebsVolumeQuotaCodes are the quota codes of the storage and, for
+// provisioned IOPS volume types, IOPS quotas of every EBS volume type
+var ebsVolumeQuotaCodes = map[string]struct {
+	storage string
+	iops    string
+}{
+	ec2.VolumeTypeGp2: {storage: "L-D18FCD1D"},
+	ec2.VolumeTypeGp3: {storage: "L-7A658B76"},
+	ec2.VolumeTypeIo1: {storage: "L-FD252861", iops: "L-B3A130E6"},
+	ec2.VolumeTypeIo2: {storage: "L-09BD8365", iops: "L-8D977E7E"},
+	ec2.VolumeTypeSt1: {storage: "L-82ACEF56"},
+	ec2.VolumeTypeSc1: {storage: "L-17AF77E8"},
+}
+
+// volumesCache caches the EBS volumes so the usage checks of all
+// volume types don't describe them separately
+type volumesCache struct {
+	client      ec2iface.EC2API
+	ttl         time.Duration
+	lock        sync.Mutex
+	volumes     []*ec2.Volume
+	lastRefresh time.Time
+}
+
+func newVolumesCache(client ec2iface.EC2API) *volumesCache {
+	return &volumesCache{client: client, ttl: instancesCacheTTL}
+}
+
+// Volumes returns the EBS volumes, describing them if the cached
+// volumes are older than the cache's TTL, or an error
+func (c *volumesCache) Volumes() ([]*ec2.Volume, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.volumes != nil && time.Since(c.lastRefresh) < c.ttl {
+		return c.volumes, nil
+	}
+
+	volumes := []*ec2.Volume{}
+	params := &ec2.DescribeVolumesInput{}
+	err := c.client.DescribeVolumesPages(params,
+		func(page *ec2.DescribeVolumesOutput, lastPage bool) bool {
+			if page != nil {
+				volumes = append(volumes, page.Volumes...)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	c.volumes = volumes
+	c.lastRefresh = time.Now()
+	return volumes, nil
+}
+
+// VolumeStorageUsageCheck implements the UsageCheck interface for the
+// storage of an EBS volume type
+type VolumeStorageUsageCheck struct {
+	volumes    *volumesCache
+	volumeType string
+}
+
+// Usage returns usage for the storage of the volume type as the size
+// in TiB of all volumes of the type in the region or an error
+func (c *VolumeStorageUsageCheck) Usage() ([]QuotaUsage, error) {
+	volumes, err := c.volumes.Volumes()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	var gib int64
+	for _, volume := range volumes {
+		if aws.StringValue(volume.VolumeType) == c.volumeType {
+			gib += aws.Int64Value(volume.Size)
+		}
+	}
+
+	usage := []QuotaUsage{
+		{
+			Name:        c.name(),
+			Description: c.description(),
+			Usage:       float64(gib) / gibPerTiB,
+		},
+	}
+	return usage, nil
+}
+
+// Definitions returns the storage quota of the volume type
+func (c *VolumeStorageUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: c.name(), Description: c.description()}}
+}
+
+func (c *VolumeStorageUsageCheck) name() string {
+	return fmt.Sprintf("ebs_%s_storage_tib", c.volumeType)
+}
+
+func (c *VolumeStorageUsageCheck) description() string {
+	return fmt.Sprintf("storage of EBS %s volumes in TiB", c.volumeType)
+}
+
+// VolumeIOPSUsageCheck implements the UsageCheck interface for the
+// provisioned IOPS of an EBS volume type
+type VolumeIOPSUsageCheck struct {
+	volumes    *volumesCache
+	volumeType string
+}
+
+// Usage returns usage for the IOPS of the volume type as the IOPS
+// provisioned for all volumes of the type in the region or an error
+func (c *VolumeIOPSUsageCheck) Usage() ([]QuotaUsage, error) {
+	volumes, err := c.volumes.Volumes()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	var iops int64
+	for _, volume := range volumes {
+		if aws.StringValue(volume.VolumeType) == c.volumeType {
+			iops += aws.Int64Value(volume.Iops)
+		}
+	}
+
+	usage := []QuotaUsage{
+		{
+			Name:        c.name(),
+			Description: c.description(),
+			Usage:       float64(iops),
+		},
+	}
+	return usage, nil
+}
+
+// Definitions returns the IOPS quota of the volume type
+func (c *VolumeIOPSUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: c.name(), Description: c.description()}}
+}
+
+func (c *VolumeIOPSUsageCheck) name() string {
+	return fmt.Sprintf("ebs_%s_iops", c.volumeType)
+}
+
+func (c *VolumeIOPSUsageCheck) description() string {
+	return fmt.Sprintf("provisioned IOPS of EBS %s volumes", c.volumeType)
+}
+
+// SnapshotsPerRegionUsageCheck implements the UsageCheck interface
+// for EBS snapshots per region
+type SnapshotsPerRegionUsageCheck struct {
+	client ec2iface.EC2API
+}
+
+// Usage returns usage for EBS snapshots per region as the number of
+// snapshots owned by the account in the region or an error
+func (c *SnapshotsPerRegionUsageCheck) Usage() ([]QuotaUsage, error) {
+	numSnapshots := 0
+
+	params := &ec2.DescribeSnapshotsInput{OwnerIds: []*string{aws.String(selfSnapshotOwner)}}
+	err := c.client.DescribeSnapshotsPages(params,
+		func(page *ec2.DescribeSnapshotsOutput, lastPage bool) bool {
+			if page != nil {
+				numSnapshots += len(page.Snapshots)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	usage := []QuotaUsage{
+		{
+			Name:        ebsSnapshotsPerRegionName,
+			Description: ebsSnapshotsPerRegionDesc,
+			Usage:       float64(numSnapshots),
+		},
+	}
+	return usage, nil
+}
+
+// Definitions returns the EBS snapshots per region quota
+func (c *SnapshotsPerRegionUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: ebsSnapshotsPerRegionName, Description: ebsSnapshotsPerRegionDesc}}
+}
+
+// ebsUsageChecks returns the storage and IOPS usage checks of all EBS
+// volume types, all sharing `volumes`, and the snapshots usage check
+// by quota code
+func ebsUsageChecks(client ec2iface.EC2API, volumes *volumesCache) map[string]UsageCheck {
+	checks := map[string]UsageCheck{
+		"L-309BACF6": &SnapshotsPerRegionUsageCheck{client},
+	}
+	for volumeType, codes := range ebsVolumeQuotaCodes {
+		checks[codes.storage] = &VolumeStorageUsageCheck{volumes: volumes, volumeType: volumeType}
+		if codes.iops != "" {
+			checks[codes.iops] = &VolumeIOPSUsageCheck{volumes: volumes, volumeType: volumeType}
+		}
+	}
+	return checks
+}
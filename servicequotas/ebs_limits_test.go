@@ -0,0 +1,140 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func (m *mockEC2Client) DescribeVolumesPages(input *ec2.DescribeVolumesInput, fn func(*ec2.DescribeVolumesOutput, bool) bool) error {
+	m.DescribeVolumesCalls++
+	fn(m.DescribeVolumesResponse, true)
+	return m.err
+}
+
+func (m *mockEC2Client) DescribeSnapshotsPages(input *ec2.DescribeSnapshotsInput, fn func(*ec2.DescribeSnapshotsOutput, bool) bool) error {
+	m.SnapshotsOwnerIds = input.OwnerIds
+	fn(m.DescribeSnapshotsResponse, true)
+	return m.err
+}
+
+func testVolumes() *ec2.DescribeVolumesOutput {
+	volume := func(volumeType string, size, iops int64) *ec2.Volume {
+		return &ec2.Volume{VolumeType: aws.String(volumeType), Size: aws.Int64(size), Iops: aws.Int64(iops)}
+	}
+
+	return &ec2.DescribeVolumesOutput{
+		Volumes: []*ec2.Volume{
+			volume("gp3", 1024, 3000),
+			volume("gp3", 512, 3000),
+			volume("io2", 100, 16000),
+			volume("io2", 200, 32000),
+			volume("st1", 2048, 0),
+		},
+	}
+}
+
+func TestVolumesCache(t *testing.T) {
+	mockClient := &mockEC2Client{DescribeVolumesResponse: testVolumes()}
+	cache := newVolumesCache(mockClient)
+
+	for i := 0; i < 3; i++ {
+		volumes, err := cache.Volumes()
+
+		assert.NoError(t, err)
+		assert.Len(t, volumes, 5)
+	}
+	assert.Equal(t, 1, mockClient.DescribeVolumesCalls)
+
+	cache.lastRefresh = time.Now().Add(-instancesCacheTTL)
+	_, err := cache.Volumes()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, mockClient.DescribeVolumesCalls)
+}
+
+func TestEBSUsageChecksWithError(t *testing.T) {
+	mockClient := &mockEC2Client{err: errors.New("some err")}
+
+	for code, check := range ebsUsageChecks(mockClient, newVolumesCache(mockClient)) {
+		t.Run(code, func(t *testing.T) {
+			usage, err := check.Usage()
+
+			assert.Error(t, err)
+			assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+			assert.Nil(t, usage)
+		})
+	}
+}
+
+func TestEBSUsageChecks(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeVolumesResponse: testVolumes(),
+		DescribeSnapshotsResponse: &ec2.DescribeSnapshotsOutput{
+			Snapshots: []*ec2.Snapshot{{SnapshotId: aws.String("snap-1")}, {SnapshotId: aws.String("snap-2")}},
+		},
+	}
+	checks := ebsUsageChecks(mockClient, newVolumesCache(mockClient))
+
+	testCases := []struct {
+		code          string
+		expectedUsage QuotaUsage
+	}{
+		{
+			code: "L-7A658B76",
+			expectedUsage: QuotaUsage{
+				Name:        "ebs_gp3_storage_tib",
+				Description: "storage of EBS gp3 volumes in TiB",
+				Usage:       1.5,
+			},
+		},
+		{
+			code: "L-D18FCD1D",
+			expectedUsage: QuotaUsage{
+				Name:        "ebs_gp2_storage_tib",
+				Description: "storage of EBS gp2 volumes in TiB",
+				Usage:       0,
+			},
+		},
+		{
+			code: "L-82ACEF56",
+			expectedUsage: QuotaUsage{
+				Name:        "ebs_st1_storage_tib",
+				Description: "storage of EBS st1 volumes in TiB",
+				Usage:       2,
+			},
+		},
+		{
+			code: "L-8D977E7E",
+			expectedUsage: QuotaUsage{
+				Name:        "ebs_io2_iops",
+				Description: "provisioned IOPS of EBS io2 volumes",
+				Usage:       48000,
+			},
+		},
+		{
+			code: "L-309BACF6",
+			expectedUsage: QuotaUsage{
+				Name:        ebsSnapshotsPerRegionName,
+				Description: ebsSnapshotsPerRegionDesc,
+				Usage:       2,
+			},
+		},
+	}
+
+	assert.Len(t, checks, 9)
+	for _, tc := range testCases {
+		t.Run(tc.code, func(t *testing.T) {
+			usage, err := checks[tc.code].Usage()
+
+			assert.NoError(t, err)
+			assert.Equal(t, []QuotaUsage{tc.expectedUsage}, usage)
+			assert.Equal(t, []QuotaDefinition{{Name: tc.expectedUsage.Name, Description: tc.expectedUsage.Description}}, checks[tc.code].Definitions())
+		})
+	}
+	assert.Equal(t, []*string{aws.String("self")}, mockClient.SnapshotsOwnerIds)
+}
@@ -2,12 +2,8 @@ package servicequotas
 
 import (
 	"fmt"
-	"math"
-	"strconv"
 
-	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
-	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 )
 
 // Not all quota limits here are reported under "ec2", but all of the
@@ -24,134 +20,110 @@ const (
 
 	securityGroupsPerRegionName = "security_groups_per_region"
 	securityGroupsPerRegionDesc = "security groups per region"
-
-	spotInstanceRequestsName = "spot_instance_requests"
-	spotInstanceRequestsDesc = "spot instance requests"
-
-	onDemandInstanceRequestsName = "ondemand_instance_requests"
-	onDemandInstanceRequestsDesc = "ondemand instance requests"
-
-	availableIPsPerSubnetName = "available_ips_per_subnet"
-	availableIPsPerSubnetDesc = "available IPs per subnet"
 )
 
 // RulesPerSecurityGroupUsageCheck implements the UsageCheck interface
 // for rules per security group
 type RulesPerSecurityGroupUsageCheck struct {
-	client ec2iface.EC2API
+	securityGroups *securityGroupsCache
 }
 
 // Usage returns the usage for each security group ID with the usage
-// value being the sum of their inbound and outbound rules or an error
+// value being the number of their inbound and outbound rules as
+// counted by AWS or an error
 func (c *RulesPerSecurityGroupUsageCheck) Usage() ([]QuotaUsage, error) {
-	quotaUsages := []QuotaUsage{}
-
-	params := &ec2.DescribeSecurityGroupsInput{}
-	err := c.client.DescribeSecurityGroupsPages(params,
-		func(page *ec2.DescribeSecurityGroupsOutput, lastPage bool) bool {
-			if page != nil {
-				for _, group := range page.SecurityGroups {
-					var inboundRules int
-					var outboundRules int
-
-					tags := ec2TagsToQuotaUsageTags(group.Tags)
-
-					for _, rule := range group.IpPermissions {
-						inboundRules += len(rule.IpRanges)
-						inboundRules += len(rule.UserIdGroupPairs)
-					}
+	weights, err := c.securityGroups.PrefixListWeights()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
 
-					inboundUsage := QuotaUsage{
-						Name:         inboundRulesPerSecGrpName,
-						ResourceName: group.GroupId,
-						Description:  inboundRulesPerSecGrpDesc,
-						Usage:        float64(inboundRules),
-						Tags:         tags,
-					}
+	securityGroups, err := c.securityGroups.SecurityGroups()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
 
-					for _, rule := range group.IpPermissionsEgress {
-						outboundRules += len(rule.IpRanges)
-						inboundRules += len(rule.UserIdGroupPairs)
-					}
+	quotaUsages := []QuotaUsage{}
+	for _, group := range securityGroups {
+		tags := ec2TagsToQuotaUsageTags(group.Tags)
+		inboundRules := securityGroupRuleCount(group.IpPermissions, weights)
+		outboundRules := securityGroupRuleCount(group.IpPermissionsEgress, weights)
+
+		inboundUsage := QuotaUsage{
+			Name:         inboundRulesPerSecGrpName,
+			ResourceName: group.GroupId,
+			Description:  inboundRulesPerSecGrpDesc,
+			Usage:        float64(inboundRules),
+			Tags:         tags,
+		}
 
-					outboundUsage := QuotaUsage{
-						Name:         outboundRulesPerSecGrpName,
-						ResourceName: group.GroupId,
-						Description:  outboundRulesPerSecGrpDesc,
-						Usage:        float64(outboundRules),
-						Tags:         tags,
-					}
+		outboundUsage := QuotaUsage{
+			Name:         outboundRulesPerSecGrpName,
+			ResourceName: group.GroupId,
+			Description:  outboundRulesPerSecGrpDesc,
+			Usage:        float64(outboundRules),
+			Tags:         tags,
+		}
 
-					quotaUsages = append(quotaUsages, []QuotaUsage{inboundUsage, outboundUsage}...)
-				}
-			}
-			return !lastPage
-		},
-	)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+		quotaUsages = append(quotaUsages, []QuotaUsage{inboundUsage, outboundUsage}...)
 	}
 
 	return quotaUsages, nil
 }
 
+// Definitions returns the inbound and outbound rules per security
+// group quotas
+func (c *RulesPerSecurityGroupUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{
+		{Name: inboundRulesPerSecGrpName, Description: inboundRulesPerSecGrpDesc},
+		{Name: outboundRulesPerSecGrpName, Description: outboundRulesPerSecGrpDesc},
+	}
+}
+
 // SecurityGroupsPerENIUsageCheck implements the UsageCheck interface
 // for security groups per ENI
 type SecurityGroupsPerENIUsageCheck struct {
-	client ec2iface.EC2API
+	networkInterfaces *networkInterfacesCache
 }
 
 // Usage returns usage for each Elastic Network Interface ID with the
 // usage value being the number of security groups for each ENI or an
 // error
 func (c *SecurityGroupsPerENIUsageCheck) Usage() ([]QuotaUsage, error) {
-	quotaUsages := []QuotaUsage{}
-
-	params := &ec2.DescribeNetworkInterfacesInput{}
-	err := c.client.DescribeNetworkInterfacesPages(params,
-		func(page *ec2.DescribeNetworkInterfacesOutput, lastPage bool) bool {
-			if page != nil {
-				for _, eni := range page.NetworkInterfaces {
-					usage := QuotaUsage{
-						Name:         secGroupsPerENIName,
-						ResourceName: eni.NetworkInterfaceId,
-						Description:  secGroupsPerENIDesc,
-						Usage:        float64(len(eni.Groups)),
-						Tags:         ec2TagsToQuotaUsageTags(eni.TagSet),
-					}
-					quotaUsages = append(quotaUsages, usage)
-				}
-			}
-			return !lastPage
-		},
-	)
+	networkInterfaces, err := c.networkInterfaces.NetworkInterfaces()
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
 	}
 
+	quotaUsages := []QuotaUsage{}
+	for _, eni := range networkInterfaces {
+		usage := QuotaUsage{
+			Name:         secGroupsPerENIName,
+			ResourceName: eni.NetworkInterfaceId,
+			Description:  secGroupsPerENIDesc,
+			Usage:        float64(len(eni.Groups)),
+			Tags:         ec2TagsToQuotaUsageTags(eni.TagSet),
+		}
+		quotaUsages = append(quotaUsages, usage)
+	}
+
 	return quotaUsages, nil
 }
 
+// Definitions returns the security groups per network interface quota
+func (c *SecurityGroupsPerENIUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: secGroupsPerENIName, Description: secGroupsPerENIDesc}}
+}
+
 // SecurityGroupsPerRegionUsageCheck implements the UsageCheck interface
 // for security groups per region
 type SecurityGroupsPerRegionUsageCheck struct {
-	client ec2iface.EC2API
+	securityGroups *securityGroupsCache
 }
 
 // Usage returns usage for security groups per region as the number of
 // all security groups for the region specified with `cfgs` or an error
 func (c *SecurityGroupsPerRegionUsageCheck) Usage() ([]QuotaUsage, error) {
-	numGroups := 0
-
-	params := &ec2.DescribeSecurityGroupsInput{}
-	err := c.client.DescribeSecurityGroupsPages(params,
-		func(page *ec2.DescribeSecurityGroupsOutput, lastPage bool) bool {
-			if page != nil {
-				numGroups += len(page.SecurityGroups)
-			}
-			return !lastPage
-		},
-	)
+	securityGroups, err := c.securityGroups.SecurityGroups()
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
 	}
@@ -160,198 +132,15 @@ func (c *SecurityGroupsPerRegionUsageCheck) Usage() ([]QuotaUsage, error) {
 		{
 			Name:        securityGroupsPerRegionName,
 			Description: securityGroupsPerRegionDesc,
-			Usage:       float64(numGroups),
-		},
-	}
-	return usage, nil
-}
-
-func standardInstanceTypeFilter() *ec2.Filter {
-	return &ec2.Filter{
-		Name: aws.String("instance-type"),
-		Values: []*string{
-			aws.String("a*"),
-			aws.String("c*"),
-			aws.String("d*"),
-			aws.String("h*"),
-			aws.String("i*"),
-			aws.String("m*"),
-			aws.String("r*"),
-			aws.String("t*"),
-			aws.String("z*"),
-		},
-	}
-}
-
-func activeInstanceFilter() *ec2.Filter {
-	return &ec2.Filter{
-		Name: aws.String("instance-state-name"),
-		Values: []*string{
-			aws.String("pending"),
-			aws.String("running"),
-		},
-	}
-}
-
-// standardInstancesCPUs returns the number of vCPUs for all standard
-// (A, C, D, H, I, M, R, T, Z) EC2 instances
-// Note that we are working out the number of vCPUs for each instance
-// here because instances can have custom CPU options specified during
-// launch. More information can be found at
-// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/instance-optimize-cpu.html
-func standardInstancesCPUs(ec2Service ec2iface.EC2API, spotInstances bool) (int64, error) {
-	var totalvCPUs int64
-	instanceTypeFilter := standardInstanceTypeFilter()
-	instanceStateFilter := activeInstanceFilter()
-	filters := []*ec2.Filter{instanceTypeFilter, instanceStateFilter}
-
-	// According to the AWS docs we should be able to filter
-	// "scheduled" instances as well, but that does not work so we
-	// are using filters only for the spot instances
-	if spotInstances {
-		spotFilter := &ec2.Filter{
-			Name:   aws.String("instance-lifecycle"),
-			Values: []*string{aws.String("spot")},
-		}
-		filters = append(filters, spotFilter)
-	}
-
-	params := &ec2.DescribeInstancesInput{Filters: filters}
-	err := ec2Service.DescribeInstancesPages(params,
-		func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
-			if page != nil {
-				for _, reservation := range page.Reservations {
-					for _, instance := range reservation.Instances {
-						// InstanceLifecycle is nil for On-Demand instances
-						if !spotInstances && instance.InstanceLifecycle != nil {
-							continue
-						}
-
-						cpuOptions := instance.CpuOptions
-						if cpuOptions.CoreCount != nil && cpuOptions.ThreadsPerCore != nil {
-							numvCPUs := *cpuOptions.CoreCount * *cpuOptions.ThreadsPerCore
-							totalvCPUs += numvCPUs
-						}
-					}
-				}
-			}
-			return !lastPage
-		},
-	)
-	if err != nil {
-		return 0, err
-	}
-
-	return totalvCPUs, nil
-}
-
-// StandardSpotInstanceRequestsUsageCheck implements the UsageCheck interface
-// for standard spot instance requests
-type StandardSpotInstanceRequestsUsageCheck struct {
-	client ec2iface.EC2API
-}
-
-// Usage returns vCPU usage for all standard (A, C, D, H, I, M, R, T,
-// Z) spot instance requests and usage or an error
-// vCPUs are returned instead of the number of images due to the
-// service quota reporting the number of vCPUs
-// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/using-spot-limits.html
-func (c *StandardSpotInstanceRequestsUsageCheck) Usage() ([]QuotaUsage, error) {
-	cpus, err := standardInstancesCPUs(c.client, true)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
-	}
-
-	usage := []QuotaUsage{
-		{
-			Name:        spotInstanceRequestsName,
-			Description: spotInstanceRequestsDesc,
-			Usage:       float64(cpus),
-		},
-	}
-	return usage, nil
-}
-
-// RunningOnDemandStandardInstancesUsageCheck implements the UsageCheck interface
-// for standard on-demand instances
-type RunningOnDemandStandardInstancesUsageCheck struct {
-	client ec2iface.EC2API
-}
-
-// Usage returns vCPU usage for all running on-demand standard (A, C,
-// D, H, I, M, R, T, Z) instances or an error vCPUs are returned instead
-// of the number of images due to the service quota reporting the number
-// of vCPUs
-// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/using-spot-limits.html
-func (c *RunningOnDemandStandardInstancesUsageCheck) Usage() ([]QuotaUsage, error) {
-	cpus, err := standardInstancesCPUs(c.client, false)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
-	}
-
-	usage := []QuotaUsage{
-		{
-			Name:        onDemandInstanceRequestsName,
-			Description: onDemandInstanceRequestsDesc,
-			Usage:       float64(cpus),
+			Usage:       float64(len(securityGroups)),
 		},
 	}
 	return usage, nil
 }
 
-// AvailableIpsPerSubnetUsageCheck implements the UsageCheckInterface
-// for available IPs per subnet
-type AvailableIpsPerSubnetUsageCheck struct {
-	client ec2iface.EC2API
-}
-
-// Usage returns the usage for each subnet ID with the usage value
-// being the number of available IPv4 addresses in that subnet or
-// an error
-// Note that the Description of the resource here is constructed
-// using `availableIPsPerSubnetDesc` defined previously as well as
-// the subnet's CIDR block
-func (c *AvailableIpsPerSubnetUsageCheck) Usage() ([]QuotaUsage, error) {
-	availabilityInfos := []QuotaUsage{}
-	var conversionErr error
-
-	params := &ec2.DescribeSubnetsInput{}
-	err := c.client.DescribeSubnetsPages(params,
-		func(page *ec2.DescribeSubnetsOutput, lastPage bool) bool {
-			if page != nil {
-				for _, subnet := range page.Subnets {
-					cidrBlock := *subnet.CidrBlock
-					blockedBits, err := strconv.Atoi(cidrBlock[len(cidrBlock)-2:])
-					if err != nil {
-						conversionErr = fmt.Errorf("%w: %s", ErrFailedToConvertCidr, err)
-						// stops paging if strconv experiences an error
-						return true
-					}
-					maxNumOfIPs := math.Pow(2, 32-float64(blockedBits))
-					usage := float64(maxNumOfIPs - float64(*subnet.AvailableIpAddressCount))
-					availabilityInfo := QuotaUsage{
-						Name:         availableIPsPerSubnetName,
-						ResourceName: subnet.SubnetId,
-						Description:  availableIPsPerSubnetDesc,
-						Usage:        usage,
-						Quota:        float64(maxNumOfIPs),
-						Tags:         ec2TagsToQuotaUsageTags(subnet.Tags),
-					}
-					availabilityInfos = append(availabilityInfos, availabilityInfo)
-				}
-			}
-			return !lastPage
-		},
-	)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
-	}
-
-	if conversionErr != nil {
-		return nil, conversionErr
-	}
-
-	return availabilityInfos, nil
+// Definitions returns the security groups per region quota
+func (c *SecurityGroupsPerRegionUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: securityGroupsPerRegionName, Description: securityGroupsPerRegionDesc}}
 }
 
 func ec2TagsToQuotaUsageTags(tags []*ec2.Tag) map[string]string {
@@ -10,33 +10,31 @@ import (
 )
 
 func (m *mockEC2Client) DescribeSecurityGroupsPages(input *ec2.DescribeSecurityGroupsInput, fn func(*ec2.DescribeSecurityGroupsOutput, bool) bool) error {
+	m.DescribeSecurityGroupsCalls++
 	fn(m.DescribeSecurityGroupsResponse, true)
 	return m.err
 }
 
 func (m *mockEC2Client) DescribeNetworkInterfacesPages(input *ec2.DescribeNetworkInterfacesInput, fn func(*ec2.DescribeNetworkInterfacesOutput, bool) bool) error {
+	m.DescribeNetworkInterfacesCalls++
 	fn(m.DescribeNetworkInterfacesResponse, true)
 	return m.err
 }
 
 func (m *mockEC2Client) DescribeInstancesPages(input *ec2.DescribeInstancesInput, fn func(*ec2.DescribeInstancesOutput, bool) bool) error {
 	m.InstancesFilters = input.Filters
+	m.DescribeInstancesCalls++
 	fn(m.DescribeInstancesResponse, true)
 	return m.err
 }
 
-func (m *mockEC2Client) DescribeSubnetsPages(input *ec2.DescribeSubnetsInput, fn func(*ec2.DescribeSubnetsOutput, bool) bool) error {
-	fn(m.DescribeSubnetsResponse, true)
-	return m.err
-}
-
 func TestRulesPerSecurityGroupUsageWithError(t *testing.T) {
 	mockClient := &mockEC2Client{
 		err:                            errors.New("some err"),
 		DescribeSecurityGroupsResponse: nil,
 	}
 
-	check := RulesPerSecurityGroupUsageCheck{mockClient}
+	check := RulesPerSecurityGroupUsageCheck{newSecurityGroupsCache(mockClient)}
 	usage, err := check.Usage()
 
 	assert.Error(t, err)
@@ -101,6 +99,30 @@ func TestRulesPerSecurityGroupUsage(t *testing.T) {
 						},
 					},
 				},
+				{
+					GroupId: aws.String("groupwithprefixlists"),
+					IpPermissions: []*ec2.IpPermission{
+						{
+							IpProtocol: aws.String("tcp"),
+							FromPort:   aws.Int64(443),
+							ToPort:     aws.Int64(443),
+							PrefixListIds: []*ec2.PrefixListId{
+								{PrefixListId: aws.String("pl-0123456789abcdef0")},
+							},
+							Ipv6Ranges: []*ec2.Ipv6Range{
+								{CidrIpv6: aws.String("::/0")},
+							},
+						},
+					},
+					IpPermissionsEgress: []*ec2.IpPermission{
+						{
+							IpProtocol: aws.String("-1"),
+							UserIdGroupPairs: []*ec2.UserIdGroupPair{
+								{GroupId: aws.String("sg-0afb91d177e53ae1d")},
+							},
+						},
+					},
+				},
 			},
 			expectedUsage: []QuotaUsage{
 				{
@@ -127,6 +149,18 @@ func TestRulesPerSecurityGroupUsage(t *testing.T) {
 					Description:  outboundRulesPerSecGrpDesc,
 					Usage:        1,
 				},
+				{
+					Name:         inboundRulesPerSecGrpName,
+					ResourceName: aws.String("groupwithprefixlists"),
+					Description:  inboundRulesPerSecGrpDesc,
+					Usage:        11,
+				},
+				{
+					Name:         outboundRulesPerSecGrpName,
+					ResourceName: aws.String("groupwithprefixlists"),
+					Description:  outboundRulesPerSecGrpDesc,
+					Usage:        1,
+				},
 			},
 		},
 	}
@@ -138,9 +172,14 @@ func TestRulesPerSecurityGroupUsage(t *testing.T) {
 				DescribeSecurityGroupsResponse: &ec2.DescribeSecurityGroupsOutput{
 					SecurityGroups: tc.securityGroups,
 				},
+				DescribeManagedPrefixListsResponse: &ec2.DescribeManagedPrefixListsOutput{
+					PrefixLists: []*ec2.ManagedPrefixList{
+						{PrefixListId: aws.String("pl-0123456789abcdef0"), MaxEntries: aws.Int64(10)},
+					},
+				},
 			}
 
-			check := RulesPerSecurityGroupUsageCheck{mockClient}
+			check := RulesPerSecurityGroupUsageCheck{newSecurityGroupsCache(mockClient)}
 			usage, err := check.Usage()
 
 			assert.NoError(t, err)
@@ -155,7 +194,7 @@ func TestSecurityGroupsPerENIUsageWithError(t *testing.T) {
 		DescribeNetworkInterfacesResponse: nil,
 	}
 
-	check := SecurityGroupsPerENIUsageCheck{mockClient}
+	check := SecurityGroupsPerENIUsageCheck{newNetworkInterfacesCache(mockClient)}
 	usage, err := check.Usage()
 
 	assert.Error(t, err)
@@ -211,7 +250,7 @@ func TestSecurityGroupsPerENIUsage(t *testing.T) {
 				},
 			}
 
-			check := SecurityGroupsPerENIUsageCheck{mockClient}
+			check := SecurityGroupsPerENIUsageCheck{newNetworkInterfacesCache(mockClient)}
 			usage, err := check.Usage()
 
 			assert.NoError(t, err)
@@ -226,7 +265,7 @@ func TestSecurityGroupsPerRegionUsageWithError(t *testing.T) {
 		DescribeSecurityGroupsResponse: nil,
 	}
 
-	check := SecurityGroupsPerRegionUsageCheck{mockClient}
+	check := SecurityGroupsPerRegionUsageCheck{newSecurityGroupsCache(mockClient)}
 	usage, err := check.Usage()
 
 	assert.Error(t, err)
@@ -280,245 +319,7 @@ func TestSecurityGroupsPerRegionUsage(t *testing.T) {
 				},
 			}
 
-			check := SecurityGroupsPerRegionUsageCheck{mockClient}
-			usage, err := check.Usage()
-
-			assert.NoError(t, err)
-			assert.Equal(t, tc.expectedUsage, usage)
-		})
-	}
-}
-
-func TestStandardInstancesCPUsWithError(t *testing.T) {
-	mockClient := &mockEC2Client{
-		err:                       errors.New("some err"),
-		DescribeInstancesResponse: nil,
-	}
-
-	cpus, err := standardInstancesCPUs(mockClient, true)
-
-	assert.Error(t, err)
-	assert.Equal(t, int64(0), cpus)
-}
-
-func TestStandardInstancesCPUsFilters(t *testing.T) {
-	instanceTypeFilter := standardInstanceTypeFilter()
-	instanceStateFilter := activeInstanceFilter()
-
-	testCases := []struct {
-		name            string
-		spotInstances   bool
-		expectedFilters []*ec2.Filter
-	}{
-		{
-			name:          "ForSpotInstances",
-			spotInstances: true,
-			expectedFilters: []*ec2.Filter{
-				instanceTypeFilter,
-				instanceStateFilter,
-				{
-					Name:   aws.String("instance-lifecycle"),
-					Values: []*string{aws.String("spot")},
-				},
-			},
-		},
-		{
-			name:            "ForOnDemandInstances",
-			spotInstances:   false,
-			expectedFilters: []*ec2.Filter{instanceTypeFilter, instanceStateFilter},
-		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			mockClient := &mockEC2Client{err: nil, DescribeInstancesResponse: nil}
-
-			cpus, err := standardInstancesCPUs(mockClient, tc.spotInstances)
-
-			assert.NoError(t, err)
-			assert.Equal(t, int64(0), cpus)
-			assert.Equal(t, mockClient.InstancesFilters, tc.expectedFilters)
-		})
-	}
-}
-
-func TestStandardInstancesCPUs(t *testing.T) {
-	mockClient := &mockEC2Client{
-		err: nil,
-		DescribeInstancesResponse: &ec2.DescribeInstancesOutput{
-			Reservations: []*ec2.Reservation{
-				{
-					Instances: []*ec2.Instance{
-						{
-							InstanceLifecycle: aws.String("spot"),
-							CpuOptions: &ec2.CpuOptions{
-								CoreCount:      aws.Int64(4),
-								ThreadsPerCore: aws.Int64(2),
-							},
-						},
-					},
-				},
-				{
-					Instances: []*ec2.Instance{
-						{
-							CpuOptions: &ec2.CpuOptions{
-								CoreCount:      aws.Int64(2),
-								ThreadsPerCore: aws.Int64(2),
-							},
-						},
-						{
-							CpuOptions: &ec2.CpuOptions{
-								CoreCount:      aws.Int64(4),
-								ThreadsPerCore: aws.Int64(2),
-							},
-						},
-					},
-				},
-			},
-		},
-	}
-
-	cpus, err := standardInstancesCPUs(mockClient, false)
-	assert.NoError(t, err)
-	assert.Equal(t, int64(12), cpus)
-}
-
-func TestAvailableIpsPerSubnetUsageWithError(t *testing.T) {
-	mockClient := &mockEC2Client{
-		err:                     errors.New("some err"),
-		DescribeSubnetsResponse: nil,
-	}
-
-	check := AvailableIpsPerSubnetUsageCheck{mockClient}
-	usage, err := check.Usage()
-
-	assert.Error(t, err)
-	assert.True(t, errors.Is(err, ErrFailedToGetUsage))
-	assert.Nil(t, usage)
-}
-
-func TestAvailableIpsPerSubnetUsageWithInvalidCidrConversion(t *testing.T) {
-	mockClient := &mockEC2Client{
-		DescribeSubnetsResponse: &ec2.DescribeSubnetsOutput{
-			Subnets: []*ec2.Subnet{
-				{
-					AvailabilityZone:        aws.String("eu-west-1"),
-					AvailableIpAddressCount: aws.Int64(4096),
-					CidrBlock:               aws.String("invalid-cidr"),
-					SubnetId:                aws.String("subnet-id"),
-				},
-			},
-		},
-	}
-	check := AvailableIpsPerSubnetUsageCheck{mockClient}
-	usage, err := check.Usage()
-
-	assert.Error(t, err)
-	assert.True(t, errors.Is(err, ErrFailedToConvertCidr))
-	assert.Nil(t, usage)
-}
-
-func TestAvailableIpsPerSubnetUsage(t *testing.T) {
-	testCases := []struct {
-		name          string
-		subnets       []*ec2.Subnet
-		expectedUsage []QuotaUsage
-	}{
-		{
-			name:          "WithNoSubnets",
-			subnets:       []*ec2.Subnet{},
-			expectedUsage: []QuotaUsage{},
-		},
-		{
-			name: "WithSingleSubnet",
-			subnets: []*ec2.Subnet{
-				{
-					AvailabilityZone:        aws.String("eu-west-1"),
-					AvailableIpAddressCount: aws.Int64(4096),
-					CidrBlock:               aws.String("100.10.10.0/20"),
-					SubnetId:                aws.String("subnet-id"),
-				},
-			},
-			expectedUsage: []QuotaUsage{
-				{
-					Name:         availableIPsPerSubnetName,
-					ResourceName: aws.String("subnet-id"),
-					Description:  availableIPsPerSubnetDesc,
-					Usage:        float64(0),
-					Quota:        float64(4096),
-				},
-			},
-		},
-		{
-			name: "WithMultipleSubnets",
-			subnets: []*ec2.Subnet{
-				{
-					AvailabilityZone:        aws.String("eu-west-1"),
-					AvailableIpAddressCount: aws.Int64(4096),
-					CidrBlock:               aws.String("100.10.10.0/20"),
-					SubnetId:                aws.String("subnet-id-1"),
-				},
-				{
-					AvailabilityZone:        aws.String("eu-west-1"),
-					AvailableIpAddressCount: aws.Int64(0),
-					CidrBlock:               aws.String("100.10.10.0/21"),
-					SubnetId:                aws.String("subnet-id-2"),
-				},
-				{
-					AvailabilityZone:        aws.String("eu-west-1"),
-					AvailableIpAddressCount: aws.Int64(100),
-					CidrBlock:               aws.String("100.10.10.0/21"),
-					SubnetId:                aws.String("subnet-id-2"),
-				},
-				{
-					AvailabilityZone:        aws.String("eu-west-1"),
-					AvailableIpAddressCount: aws.Int64(1024),
-					CidrBlock:               aws.String("100.10.10.0/22"),
-					SubnetId:                aws.String("subnet-id-3"),
-				},
-			},
-			expectedUsage: []QuotaUsage{
-				{
-					Name:         availableIPsPerSubnetName,
-					ResourceName: aws.String("subnet-id-1"),
-					Description:  availableIPsPerSubnetDesc,
-					Usage:        float64(0),
-					Quota:        float64(4096),
-				},
-				{
-					Name:         availableIPsPerSubnetName,
-					ResourceName: aws.String("subnet-id-2"),
-					Description:  availableIPsPerSubnetDesc,
-					Usage:        float64(2048),
-					Quota:        float64(2048),
-				},
-				{
-					Name:         availableIPsPerSubnetName,
-					ResourceName: aws.String("subnet-id-2"),
-					Description:  availableIPsPerSubnetDesc,
-					Usage:        float64(1948),
-					Quota:        float64(2048),
-				},
-				{
-					Name:         availableIPsPerSubnetName,
-					ResourceName: aws.String("subnet-id-3"),
-					Description:  availableIPsPerSubnetDesc,
-					Usage:        float64(0),
-					Quota:        float64(1024),
-				},
-			},
-		},
-	}
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			mockClient := &mockEC2Client{
-				err: nil,
-				DescribeSubnetsResponse: &ec2.DescribeSubnetsOutput{
-					Subnets: tc.subnets,
-				},
-			}
-
-			check := AvailableIpsPerSubnetUsageCheck{mockClient}
+			check := SecurityGroupsPerRegionUsageCheck{newSecurityGroupsCache(mockClient)}
 			usage, err := check.Usage()
 
 			assert.NoError(t, err)
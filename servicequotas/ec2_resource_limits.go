@@ -0,0 +1,214 @@
+package servicequotas
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+const (
+	amisPerRegionName = "amis_per_region"
+	amisPerRegionDesc = "AMIs per region"
+
+	publicAMIsPerRegionName = "public_amis_per_region"
+	publicAMIsPerRegionDesc = "public AMIs per region"
+
+	launchTemplatesPerRegionName = "launch_templates_per_region"
+	launchTemplatesPerRegionDesc = "launch templates per region"
+
+	versionsPerLaunchTemplateName = "versions_per_launch_template"
+	versionsPerLaunchTemplateDesc = "versions per launch template"
+
+	keyPairsPerRegionName = "key_pairs_per_region"
+	keyPairsPerRegionDesc = "key pairs per region"
+
+	// launchTemplatesPerRegionQuotaName,
+	// versionsPerLaunchTemplateQuotaName and keyPairsPerRegionQuotaName
+	// are the names the quotas are listed under in Service Quotas
+	launchTemplatesPerRegionQuotaName  = "Launch templates per Region"
+	versionsPerLaunchTemplateQuotaName = "Versions per launch template"
+	keyPairsPerRegionQuotaName         = "Key pairs per Region"
+	// defaultLaunchTemplatesPerRegion, defaultVersionsPerLaunchTemplate
+	// and defaultKeyPairsPerRegion are the default limits of launch
+	// templates, launch template versions and key pairs, used when the
+	// quotas are not listed
+	// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/ec2-resource-limits.html
+	defaultLaunchTemplatesPerRegion  = 5000
+	defaultVersionsPerLaunchTemplate = 10000
+	defaultKeyPairsPerRegion         = 5000
+
+	selfImageOwner = "self"
+
+	launchTemplateNameLabel = "launch_template_name"
+)
+
+// AMIsPerRegionUsageCheck implements the UsageCheck interface for
+// AMIs owned per region
+type AMIsPerRegionUsageCheck struct {
+	client ec2iface.EC2API
+	// public only counts the public AMIs
+	public bool
+}
+
+// Usage returns usage for AMIs per region as the number of AMIs owned
+// by the account in the region, or only the public ones, or an error
+func (c *AMIsPerRegionUsageCheck) Usage() ([]QuotaUsage, error) {
+	params := &ec2.DescribeImagesInput{Owners: []*string{aws.String(selfImageOwner)}}
+	if c.public {
+		params.Filters = []*ec2.Filter{
+			{
+				Name:   aws.String("is-public"),
+				Values: []*string{aws.String("true")},
+			},
+		}
+	}
+
+	numImages := 0
+	err := c.client.DescribeImagesPages(params,
+		func(page *ec2.DescribeImagesOutput, lastPage bool) bool {
+			if page != nil {
+				numImages += len(page.Images)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	usage := []QuotaUsage{
+		{
+			Name:        c.name(),
+			Description: c.description(),
+			Usage:       float64(numImages),
+		},
+	}
+	return usage, nil
+}
+
+// Definitions returns the AMIs or public AMIs per region quota
+func (c *AMIsPerRegionUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: c.name(), Description: c.description()}}
+}
+
+func (c *AMIsPerRegionUsageCheck) name() string {
+	if c.public {
+		return publicAMIsPerRegionName
+	}
+	return amisPerRegionName
+}
+
+func (c *AMIsPerRegionUsageCheck) description() string {
+	if c.public {
+		return publicAMIsPerRegionDesc
+	}
+	return amisPerRegionDesc
+}
+
+// LaunchTemplatesUsageCheck implements the UsageCheck interface for
+// launch templates per region and versions per launch template
+type LaunchTemplatesUsageCheck struct {
+	listedQuotas
+	client ec2iface.EC2API
+}
+
+func (c *LaunchTemplatesUsageCheck) serviceCode() string {
+	return "ec2"
+}
+
+// Usage returns usage for launch templates per region as the number
+// of launch templates in the region, and for each launch template ID
+// with the usage value being its latest version number, with the
+// listed quotas or their defaults, or an error. Version numbers are
+// not reused, so deleted versions are still counted
+func (c *LaunchTemplatesUsageCheck) Usage() ([]QuotaUsage, error) {
+	launchTemplates := []*ec2.LaunchTemplate{}
+
+	params := &ec2.DescribeLaunchTemplatesInput{}
+	err := c.client.DescribeLaunchTemplatesPages(params,
+		func(page *ec2.DescribeLaunchTemplatesOutput, lastPage bool) bool {
+			if page != nil {
+				launchTemplates = append(launchTemplates, page.LaunchTemplates...)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	quotaUsages := []QuotaUsage{
+		{
+			Name:        launchTemplatesPerRegionName,
+			Description: launchTemplatesPerRegionDesc,
+			Usage:       float64(len(launchTemplates)),
+			Quota:       c.quota(launchTemplatesPerRegionQuotaName, defaultLaunchTemplatesPerRegion),
+		},
+	}
+
+	versionsQuota := c.quota(versionsPerLaunchTemplateQuotaName, defaultVersionsPerLaunchTemplate)
+	for _, launchTemplate := range launchTemplates {
+		if launchTemplate.LaunchTemplateId == nil {
+			continue
+		}
+
+		quotaUsages = append(quotaUsages, QuotaUsage{
+			Name:         versionsPerLaunchTemplateName,
+			ResourceName: launchTemplate.LaunchTemplateId,
+			Description:  versionsPerLaunchTemplateDesc,
+			Usage:        float64(aws.Int64Value(launchTemplate.LatestVersionNumber)),
+			Quota:        versionsQuota,
+			Tags:         ec2TagsToQuotaUsageTags(launchTemplate.Tags),
+			Labels:       map[string]string{launchTemplateNameLabel: aws.StringValue(launchTemplate.LaunchTemplateName)},
+		})
+	}
+
+	return quotaUsages, nil
+}
+
+// Definitions returns the launch templates per region and versions
+// per launch template quotas
+func (c *LaunchTemplatesUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{
+		{Name: launchTemplatesPerRegionName, Description: launchTemplatesPerRegionDesc},
+		{Name: versionsPerLaunchTemplateName, Description: versionsPerLaunchTemplateDesc},
+	}
+}
+
+// KeyPairsPerRegionUsageCheck implements the UsageCheck interface for
+// key pairs per region
+type KeyPairsPerRegionUsageCheck struct {
+	listedQuotas
+	client ec2iface.EC2API
+}
+
+func (c *KeyPairsPerRegionUsageCheck) serviceCode() string {
+	return "ec2"
+}
+
+// Usage returns usage for key pairs per region as the number of key
+// pairs in the region, with the listed quota or its default, or an
+// error
+func (c *KeyPairsPerRegionUsageCheck) Usage() ([]QuotaUsage, error) {
+	output, err := c.client.DescribeKeyPairs(&ec2.DescribeKeyPairsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	usage := []QuotaUsage{
+		{
+			Name:        keyPairsPerRegionName,
+			Description: keyPairsPerRegionDesc,
+			Usage:       float64(len(output.KeyPairs)),
+			Quota:       c.quota(keyPairsPerRegionQuotaName, defaultKeyPairsPerRegion),
+		},
+	}
+	return usage, nil
+}
+
+// Definitions returns the key pairs per region quota
+func (c *KeyPairsPerRegionUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: keyPairsPerRegionName, Description: keyPairsPerRegionDesc}}
+}
@@ -0,0 +1,198 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	awsservicequotas "github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/stretchr/testify/assert"
+)
+
+func (m *mockEC2Client) DescribeImagesPages(input *ec2.DescribeImagesInput, fn func(*ec2.DescribeImagesOutput, bool) bool) error {
+	m.ImagesInput = input
+	fn(m.DescribeImagesResponse, true)
+	return m.err
+}
+
+func (m *mockEC2Client) DescribeLaunchTemplatesPages(input *ec2.DescribeLaunchTemplatesInput, fn func(*ec2.DescribeLaunchTemplatesOutput, bool) bool) error {
+	fn(m.DescribeLaunchTemplatesResponse, true)
+	return m.err
+}
+
+func (m *mockEC2Client) DescribeKeyPairs(input *ec2.DescribeKeyPairsInput) (*ec2.DescribeKeyPairsOutput, error) {
+	return m.DescribeKeyPairsResponse, m.err
+}
+
+func TestEC2ResourceUsageChecksWithError(t *testing.T) {
+	mockClient := &mockEC2Client{
+		err: errors.New("some err"),
+	}
+
+	testCases := []struct {
+		name  string
+		check UsageCheck
+	}{
+		{name: "AMIsPerRegion", check: &AMIsPerRegionUsageCheck{client: mockClient}},
+		{name: "LaunchTemplates", check: &LaunchTemplatesUsageCheck{client: mockClient}},
+		{name: "KeyPairsPerRegion", check: &KeyPairsPerRegionUsageCheck{client: mockClient}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			usage, err := tc.check.Usage()
+
+			assert.Error(t, err)
+			assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+			assert.Nil(t, usage)
+		})
+	}
+}
+
+func TestAMIsPerRegionUsage(t *testing.T) {
+	testCases := []struct {
+		name            string
+		public          bool
+		expectedUsage   []QuotaUsage
+		expectedFilters []*ec2.Filter
+	}{
+		{
+			name:   "AllAMIs",
+			public: false,
+			expectedUsage: []QuotaUsage{
+				{Name: amisPerRegionName, Description: amisPerRegionDesc, Usage: 2},
+			},
+			expectedFilters: nil,
+		},
+		{
+			name:   "PublicAMIs",
+			public: true,
+			expectedUsage: []QuotaUsage{
+				{Name: publicAMIsPerRegionName, Description: publicAMIsPerRegionDesc, Usage: 2},
+			},
+			expectedFilters: []*ec2.Filter{
+				{Name: aws.String("is-public"), Values: []*string{aws.String("true")}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := &mockEC2Client{
+				DescribeImagesResponse: &ec2.DescribeImagesOutput{
+					Images: []*ec2.Image{{ImageId: aws.String("ami-1")}, {ImageId: aws.String("ami-2")}},
+				},
+			}
+
+			check := AMIsPerRegionUsageCheck{client: mockClient, public: tc.public}
+			usage, err := check.Usage()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedUsage, usage)
+			assert.Equal(t, []*string{aws.String("self")}, mockClient.ImagesInput.Owners)
+			assert.Equal(t, tc.expectedFilters, mockClient.ImagesInput.Filters)
+		})
+	}
+}
+
+func TestLaunchTemplatesUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeLaunchTemplatesResponse: &ec2.DescribeLaunchTemplatesOutput{
+			LaunchTemplates: []*ec2.LaunchTemplate{
+				{
+					LaunchTemplateId:    aws.String("lt-1"),
+					LaunchTemplateName:  aws.String("workers"),
+					LatestVersionNumber: aws.Int64(3),
+					Tags:                []*ec2.Tag{{Key: aws.String("team"), Value: aws.String("images")}},
+				},
+				{
+					LaunchTemplateId:    aws.String("lt-2"),
+					LaunchTemplateName:  aws.String("bastion"),
+					LatestVersionNumber: aws.Int64(1),
+				},
+			},
+		},
+	}
+
+	check := LaunchTemplatesUsageCheck{client: mockClient}
+	check.setQuotas([]*awsservicequotas.ServiceQuota{
+		{QuotaName: aws.String("Versions per launch template"), Value: aws.Float64(12000)},
+	})
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:        launchTemplatesPerRegionName,
+			Description: launchTemplatesPerRegionDesc,
+			Usage:       2,
+			Quota:       5000,
+		},
+		{
+			Name:         versionsPerLaunchTemplateName,
+			ResourceName: aws.String("lt-1"),
+			Description:  versionsPerLaunchTemplateDesc,
+			Usage:        3,
+			Quota:        12000,
+			Tags:         map[string]string{"team": "images"},
+			Labels:       map[string]string{"launch_template_name": "workers"},
+		},
+		{
+			Name:         versionsPerLaunchTemplateName,
+			ResourceName: aws.String("lt-2"),
+			Description:  versionsPerLaunchTemplateDesc,
+			Usage:        1,
+			Quota:        12000,
+			Labels:       map[string]string{"launch_template_name": "bastion"},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
+
+func TestKeyPairsPerRegionUsage(t *testing.T) {
+	testCases := []struct {
+		name          string
+		quotas        []*awsservicequotas.ServiceQuota
+		expectedQuota float64
+	}{
+		{
+			name:          "WithDefaultQuota",
+			expectedQuota: 5000,
+		},
+		{
+			name: "WithListedQuota",
+			quotas: []*awsservicequotas.ServiceQuota{
+				{QuotaName: aws.String("Key pairs per Region"), Value: aws.Float64(8000)},
+			},
+			expectedQuota: 8000,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := &mockEC2Client{
+				DescribeKeyPairsResponse: &ec2.DescribeKeyPairsOutput{
+					KeyPairs: []*ec2.KeyPairInfo{{KeyName: aws.String("deploy")}},
+				},
+			}
+
+			check := KeyPairsPerRegionUsageCheck{client: mockClient}
+			check.setQuotas(tc.quotas)
+			usage, err := check.Usage()
+
+			expectedUsage := []QuotaUsage{
+				{
+					Name:        keyPairsPerRegionName,
+					Description: keyPairsPerRegionDesc,
+					Usage:       1,
+					Quota:       tc.expectedQuota,
+				},
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, expectedUsage, usage)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package servicequotas
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+const (
+	elasticIPsPerRegionName = "elastic_ips_per_region"
+	elasticIPsPerRegionDesc = "elastic IP addresses per region"
+
+	elasticIPsPerRegionBreakdownName = "elastic_ips_per_region_breakdown"
+	elasticIPsPerRegionBreakdownDesc = "elastic IP addresses per region by address"
+
+	vpcAddressDomain = "vpc"
+
+	publicIPLabel           = "public_ip"
+	associatedLabel         = "associated"
+	networkInterfaceIDLabel = "network_interface_id"
+)
+
+// ElasticIPsUsageCheck implements the UsageCheck interface for EC2-VPC
+// Elastic IP addresses per region
+type ElasticIPsUsageCheck struct {
+	client ec2iface.EC2API
+}
+
+// Usage returns the number of Elastic IP addresses allocated for use
+// in a VPC in the region, and a series per address labelled with
+// whether and to what it is associated, so that idle addresses can be
+// found and released, or an error
+func (c *ElasticIPsUsageCheck) Usage() ([]QuotaUsage, error) {
+	params := &ec2.DescribeAddressesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("domain"),
+				Values: []*string{aws.String(vpcAddressDomain)},
+			},
+		},
+	}
+	output, err := c.client.DescribeAddresses(params)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	addressUsages := []QuotaUsage{}
+	for _, address := range output.Addresses {
+		if address.AllocationId == nil {
+			continue
+		}
+
+		addressUsages = append(addressUsages, QuotaUsage{
+			Name:         elasticIPsPerRegionBreakdownName,
+			ResourceName: address.AllocationId,
+			Description:  elasticIPsPerRegionBreakdownDesc,
+			Usage:        1,
+			Tags:         ec2TagsToQuotaUsageTags(address.Tags),
+			Labels:       elasticIPLabels(address),
+		})
+	}
+
+	sort.Slice(addressUsages, func(i, j int) bool {
+		return *addressUsages[i].ResourceName < *addressUsages[j].ResourceName
+	})
+
+	usage := []QuotaUsage{
+		{
+			Name:        elasticIPsPerRegionName,
+			Description: elasticIPsPerRegionDesc,
+			Usage:       float64(len(addressUsages)),
+		},
+	}
+	return append(usage, addressUsages...), nil
+}
+
+// Definitions returns the Elastic IP addresses per region quota and
+// its per address breakdown
+func (c *ElasticIPsUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{
+		{Name: elasticIPsPerRegionName, Description: elasticIPsPerRegionDesc},
+		{Name: elasticIPsPerRegionBreakdownName, Description: elasticIPsPerRegionBreakdownDesc, Breakdown: true},
+	}
+}
+
+// elasticIPLabels returns the labels of the usage reported per
+// Elastic IP address
+func elasticIPLabels(address *ec2.Address) map[string]string {
+	return map[string]string{
+		publicIPLabel:           aws.StringValue(address.PublicIp),
+		associatedLabel:         strconv.FormatBool(address.AssociationId != nil),
+		instanceIDLabel:         aws.StringValue(address.InstanceId),
+		networkInterfaceIDLabel: aws.StringValue(address.NetworkInterfaceId),
+	}
+}
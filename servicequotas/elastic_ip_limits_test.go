@@ -0,0 +1,114 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func (m *mockEC2Client) DescribeAddresses(input *ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+	return m.DescribeAddressesResponse, m.err
+}
+
+func TestElasticIPsUsageWithError(t *testing.T) {
+	mockClient := &mockEC2Client{
+		err:                       errors.New("some err"),
+		DescribeAddressesResponse: nil,
+	}
+
+	check := ElasticIPsUsageCheck{mockClient}
+	usage, err := check.Usage()
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+	assert.Nil(t, usage)
+}
+
+func TestElasticIPsUsage(t *testing.T) {
+	testCases := []struct {
+		name          string
+		addresses     []*ec2.Address
+		expectedUsage []QuotaUsage
+	}{
+		{
+			name:      "WithNoAddresses",
+			addresses: []*ec2.Address{},
+			expectedUsage: []QuotaUsage{
+				{
+					Name:        elasticIPsPerRegionName,
+					Description: elasticIPsPerRegionDesc,
+					Usage:       0,
+				},
+			},
+		},
+		{
+			name: "WithAssociatedAndIdleAddresses",
+			addresses: []*ec2.Address{
+				{
+					AllocationId:       aws.String("eipalloc-2"),
+					PublicIp:           aws.String("203.0.113.2"),
+					AssociationId:      aws.String("eipassoc-2"),
+					InstanceId:         aws.String("i-2"),
+					NetworkInterfaceId: aws.String("eni-2"),
+					Tags:               []*ec2.Tag{{Key: aws.String("team"), Value: aws.String("edge")}},
+				},
+				{
+					AllocationId: aws.String("eipalloc-1"),
+					PublicIp:     aws.String("203.0.113.1"),
+				},
+			},
+			expectedUsage: []QuotaUsage{
+				{
+					Name:        elasticIPsPerRegionName,
+					Description: elasticIPsPerRegionDesc,
+					Usage:       2,
+				},
+				{
+					Name:         elasticIPsPerRegionBreakdownName,
+					ResourceName: aws.String("eipalloc-1"),
+					Description:  elasticIPsPerRegionBreakdownDesc,
+					Usage:        1,
+					Labels: map[string]string{
+						"public_ip":            "203.0.113.1",
+						"associated":           "false",
+						"instance_id":          "",
+						"network_interface_id": "",
+					},
+				},
+				{
+					Name:         elasticIPsPerRegionBreakdownName,
+					ResourceName: aws.String("eipalloc-2"),
+					Description:  elasticIPsPerRegionBreakdownDesc,
+					Usage:        1,
+					Tags:         map[string]string{"team": "edge"},
+					Labels: map[string]string{
+						"public_ip":            "203.0.113.2",
+						"associated":           "true",
+						"instance_id":          "i-2",
+						"network_interface_id": "eni-2",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := &mockEC2Client{
+				err: nil,
+				DescribeAddressesResponse: &ec2.DescribeAddressesOutput{
+					Addresses: tc.addresses,
+				},
+			}
+
+			check := ElasticIPsUsageCheck{mockClient}
+			usage, err := check.Usage()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedUsage, usage)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package servicequotas
+
+import (
+	"fmt"
+
+	logging "github.com/sirupsen/logrus"
+)
+
+const (
+	attachmentsPerInstanceName = "ebs_attachments_per_instance"
+	attachmentsPerInstanceDesc = "EBS volume attachments per instance"
+)
+
+// InstanceAttachmentsUsageCheck implements the UsageCheck interface
+// for EBS volume attachments per instance
+// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/volume_limits.html
+type InstanceAttachmentsUsageCheck struct {
+	instances     *instancesCache
+	instanceTypes *instanceTypeCatalog
+}
+
+// Usage returns the number of EBS volumes attached to every instance
+// or an error. On Nitro instances the attachment limit is shared, so
+// attached network interfaces and NVMe instance store volumes are
+// counted as well. Instances of unknown instance types are skipped
+func (c *InstanceAttachmentsUsageCheck) Usage() ([]QuotaUsage, error) {
+	instances, err := c.instances.Instances()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	quotaUsages := []QuotaUsage{}
+	for _, instance := range instances {
+		if instance.InstanceId == nil || instance.InstanceType == nil {
+			continue
+		}
+
+		info, known, err := c.instanceTypes.InstanceType(*instance.InstanceType)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+		}
+		if !known {
+			logging.Warnf("Unknown instance type (%s), skipping attachments of instance (%s)",
+				*instance.InstanceType, *instance.InstanceId)
+			continue
+		}
+
+		var attachments int64
+		for _, mapping := range instance.BlockDeviceMappings {
+			if mapping.Ebs != nil {
+				attachments++
+			}
+		}
+
+		if info.sharedAttachments {
+			attachments += int64(len(instance.NetworkInterfaces))
+			attachments += info.instanceStoreVolumes
+		}
+
+		quotaUsages = append(quotaUsages, QuotaUsage{
+			Name:         attachmentsPerInstanceName,
+			ResourceName: instance.InstanceId,
+			Description:  attachmentsPerInstanceDesc,
+			Usage:        float64(attachments),
+			Quota:        float64(info.maxEBSAttachments),
+			Tags:         ec2TagsToQuotaUsageTags(instance.Tags),
+			Labels:       instanceLabels(instance),
+		})
+	}
+
+	return quotaUsages, nil
+}
+
+// Definitions returns the EBS volume attachments per instance
+// information
+func (c *InstanceAttachmentsUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: attachmentsPerInstanceName, Description: attachmentsPerInstanceDesc}}
+}
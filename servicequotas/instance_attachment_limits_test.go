@@ -0,0 +1,102 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceAttachmentsUsageWithError(t *testing.T) {
+	mockClient := &mockEC2Client{err: errors.New("some err")}
+
+	check := InstanceAttachmentsUsageCheck{instances: newInstancesCache(mockClient), instanceTypes: newInstanceTypeCatalog(mockClient)}
+	usage, err := check.Usage()
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+	assert.Nil(t, usage)
+}
+
+func TestInstanceAttachmentsUsage(t *testing.T) {
+	ebsMapping := func(volumeID string) *ec2.InstanceBlockDeviceMapping {
+		return &ec2.InstanceBlockDeviceMapping{
+			Ebs: &ec2.EbsInstanceBlockDevice{VolumeId: aws.String(volumeID)},
+		}
+	}
+	networkInterfaces := []*ec2.InstanceNetworkInterface{
+		{NetworkInterfaceId: aws.String("eni-1")},
+		{NetworkInterfaceId: aws.String("eni-2")},
+	}
+
+	mockClient := &mockEC2Client{
+		DescribeInstancesResponse: &ec2.DescribeInstancesOutput{
+			Reservations: []*ec2.Reservation{
+				{
+					Instances: []*ec2.Instance{
+						{
+							InstanceId:          aws.String("i-nitro"),
+							InstanceType:        aws.String("m5d.2xlarge"),
+							BlockDeviceMappings: []*ec2.InstanceBlockDeviceMapping{ebsMapping("vol-1"), ebsMapping("vol-2"), ebsMapping("vol-3")},
+							NetworkInterfaces:   networkInterfaces,
+							Tags:                []*ec2.Tag{{Key: aws.String("team"), Value: aws.String("eks")}},
+						},
+						{
+							InstanceId:          aws.String("i-xen"),
+							InstanceType:        aws.String("m4.large"),
+							BlockDeviceMappings: []*ec2.InstanceBlockDeviceMapping{ebsMapping("vol-4"), {DeviceName: aws.String("/dev/sdb")}},
+							NetworkInterfaces:   networkInterfaces,
+						},
+						{
+							InstanceId:   aws.String("i-unknown"),
+							InstanceType: aws.String("m9.large"),
+						},
+					},
+				},
+			},
+		},
+		DescribeInstanceTypesResponse: &ec2.DescribeInstanceTypesOutput{
+			InstanceTypes: []*ec2.InstanceTypeInfo{
+				{
+					InstanceType: aws.String("m5d.2xlarge"),
+					Hypervisor:   aws.String("nitro"),
+					InstanceStorageInfo: &ec2.InstanceStorageInfo{
+						Disks: []*ec2.DiskInfo{{Count: aws.Int64(1)}},
+					},
+				},
+				{
+					InstanceType: aws.String("m4.large"),
+					Hypervisor:   aws.String("xen"),
+				},
+			},
+		},
+	}
+
+	check := InstanceAttachmentsUsageCheck{instances: newInstancesCache(mockClient), instanceTypes: newInstanceTypeCatalog(mockClient)}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         attachmentsPerInstanceName,
+			ResourceName: aws.String("i-nitro"),
+			Description:  attachmentsPerInstanceDesc,
+			Usage:        6,
+			Quota:        28,
+			Tags:         map[string]string{"team": "eks"},
+			Labels:       map[string]string{"instance_id": "i-nitro", "instance_type": "m5d.2xlarge"},
+		},
+		{
+			Name:         attachmentsPerInstanceName,
+			ResourceName: aws.String("i-xen"),
+			Description:  attachmentsPerInstanceDesc,
+			Usage:        1,
+			Quota:        40,
+			Labels:       map[string]string{"instance_id": "i-xen", "instance_type": "m4.large"},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
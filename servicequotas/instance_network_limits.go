@@ -0,0 +1,105 @@
+package servicequotas
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	logging "github.com/sirupsen/logrus"
+)
+
+const (
+	networkInterfacesPerInstanceName = "network_interfaces_per_instance"
+	networkInterfacesPerInstanceDesc = "network interfaces per instance"
+
+	privateIPsPerNetworkInterfaceName = "private_ips_per_network_interface"
+	privateIPsPerNetworkInterfaceDesc = "private IPv4 addresses per network interface"
+
+	instanceIDLabel   = "instance_id"
+	instanceTypeLabel = "instance_type"
+)
+
+// InstanceNetworkInterfacesUsageCheck implements the UsageCheck
+// interface for network interfaces per instance and private IPv4
+// addresses per network interface, which are limited by the instance
+// type
+// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/using-eni.html#AvailableIpPerENI
+type InstanceNetworkInterfacesUsageCheck struct {
+	instances     *instancesCache
+	instanceTypes *instanceTypeCatalog
+}
+
+// Usage returns the number of network interfaces attached to every
+// instance and the number of private IPv4 addresses assigned to every
+// network interface of an instance or an error. Delegated /28
+// prefixes count as a single address, like they do against the
+// limit. Instances of unknown instance types are skipped
+func (c *InstanceNetworkInterfacesUsageCheck) Usage() ([]QuotaUsage, error) {
+	instances, err := c.instances.Instances()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	quotaUsages := []QuotaUsage{}
+	for _, instance := range instances {
+		if instance.InstanceId == nil || instance.InstanceType == nil {
+			continue
+		}
+
+		info, known, err := c.instanceTypes.InstanceType(*instance.InstanceType)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+		}
+		if !known {
+			logging.Warnf("Unknown instance type (%s), skipping network interfaces of instance (%s)",
+				*instance.InstanceType, *instance.InstanceId)
+			continue
+		}
+
+		tags := ec2TagsToQuotaUsageTags(instance.Tags)
+
+		quotaUsages = append(quotaUsages, QuotaUsage{
+			Name:         networkInterfacesPerInstanceName,
+			ResourceName: instance.InstanceId,
+			Description:  networkInterfacesPerInstanceDesc,
+			Usage:        float64(len(instance.NetworkInterfaces)),
+			Quota:        float64(info.maxNetworkInterfaces),
+			Tags:         tags,
+			Labels:       instanceLabels(instance),
+		})
+
+		for _, eni := range instance.NetworkInterfaces {
+			if eni.NetworkInterfaceId == nil {
+				continue
+			}
+
+			quotaUsages = append(quotaUsages, QuotaUsage{
+				Name:         privateIPsPerNetworkInterfaceName,
+				ResourceName: eni.NetworkInterfaceId,
+				Description:  privateIPsPerNetworkInterfaceDesc,
+				Usage:        float64(len(eni.PrivateIpAddresses) + len(eni.Ipv4Prefixes)),
+				Quota:        float64(info.ipv4AddressesPerInterface),
+				Tags:         tags,
+				Labels:       instanceLabels(instance),
+			})
+		}
+	}
+
+	return quotaUsages, nil
+}
+
+// Definitions returns the network interfaces per instance and private
+// IPs per network interface information
+func (c *InstanceNetworkInterfacesUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{
+		{Name: networkInterfacesPerInstanceName, Description: networkInterfacesPerInstanceDesc},
+		{Name: privateIPsPerNetworkInterfaceName, Description: privateIPsPerNetworkInterfaceDesc},
+	}
+}
+
+// instanceLabels returns the labels of usage reported per instance
+func instanceLabels(instance *ec2.Instance) map[string]string {
+	return map[string]string{
+		instanceIDLabel:   *instance.InstanceId,
+		instanceTypeLabel: *instance.InstanceType,
+	}
+}
@@ -0,0 +1,113 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceNetworkInterfacesUsageWithError(t *testing.T) {
+	mockClient := &mockEC2Client{err: errors.New("some err")}
+
+	check := InstanceNetworkInterfacesUsageCheck{instances: newInstancesCache(mockClient), instanceTypes: newInstanceTypeCatalog(mockClient)}
+	usage, err := check.Usage()
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+	assert.Nil(t, usage)
+}
+
+func TestInstanceNetworkInterfacesUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeInstancesResponse: &ec2.DescribeInstancesOutput{
+			Reservations: []*ec2.Reservation{
+				{
+					Instances: []*ec2.Instance{
+						{
+							InstanceId:   aws.String("i-1"),
+							InstanceType: aws.String("m5.large"),
+							Tags:         []*ec2.Tag{{Key: aws.String("team"), Value: aws.String("eks")}},
+							NetworkInterfaces: []*ec2.InstanceNetworkInterface{
+								{
+									NetworkInterfaceId: aws.String("eni-1"),
+									PrivateIpAddresses: []*ec2.InstancePrivateIpAddress{
+										{PrivateIpAddress: aws.String("10.0.0.1")},
+										{PrivateIpAddress: aws.String("10.0.0.2")},
+									},
+								},
+								{
+									NetworkInterfaceId: aws.String("eni-2"),
+									PrivateIpAddresses: []*ec2.InstancePrivateIpAddress{
+										{PrivateIpAddress: aws.String("10.0.0.3")},
+									},
+									Ipv4Prefixes: []*ec2.InstanceIpv4Prefix{
+										{Ipv4Prefix: aws.String("10.0.1.0/28")},
+									},
+								},
+							},
+						},
+						{
+							InstanceId:   aws.String("i-2"),
+							InstanceType: aws.String("m9.large"),
+							NetworkInterfaces: []*ec2.InstanceNetworkInterface{
+								{NetworkInterfaceId: aws.String("eni-3")},
+							},
+						},
+					},
+				},
+			},
+		},
+		DescribeInstanceTypesResponse: &ec2.DescribeInstanceTypesOutput{
+			InstanceTypes: []*ec2.InstanceTypeInfo{
+				{
+					InstanceType: aws.String("m5.large"),
+					NetworkInfo: &ec2.NetworkInfo{
+						MaximumNetworkInterfaces:  aws.Int64(3),
+						Ipv4AddressesPerInterface: aws.Int64(10),
+					},
+				},
+			},
+		},
+	}
+
+	check := InstanceNetworkInterfacesUsageCheck{instances: newInstancesCache(mockClient), instanceTypes: newInstanceTypeCatalog(mockClient)}
+	usage, err := check.Usage()
+
+	tags := map[string]string{"team": "eks"}
+	labels := map[string]string{"instance_id": "i-1", "instance_type": "m5.large"}
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         networkInterfacesPerInstanceName,
+			ResourceName: aws.String("i-1"),
+			Description:  networkInterfacesPerInstanceDesc,
+			Usage:        2,
+			Quota:        3,
+			Tags:         tags,
+			Labels:       labels,
+		},
+		{
+			Name:         privateIPsPerNetworkInterfaceName,
+			ResourceName: aws.String("eni-1"),
+			Description:  privateIPsPerNetworkInterfaceDesc,
+			Usage:        2,
+			Quota:        10,
+			Tags:         tags,
+			Labels:       labels,
+		},
+		{
+			Name:         privateIPsPerNetworkInterfaceName,
+			ResourceName: aws.String("eni-2"),
+			Description:  privateIPsPerNetworkInterfaceDesc,
+			Usage:        2,
+			Quota:        10,
+			Tags:         tags,
+			Labels:       labels,
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
@@ -0,0 +1,223 @@
+package servicequotas
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// instanceTypeCatalogTTL is how long the instance types are cached
+// for. They rarely change, new instance types are only released
+// every few weeks
+const instanceTypeCatalogTTL = 24 * time.Hour
+
+const (
+	nitroHypervisor = "nitro"
+	// EBS volume attachment limits. On Nitro instances the limit is
+	// shared with network interfaces and NVMe instance store volumes
+	// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/volume_limits.html
+	nitroMaxAttachments  = 28
+	xenMaxEBSAttachments = 40
+	// defaultDedicatedEBSAttachments is the dedicated EBS volume
+	// attachment limit of the sizes of instance families with
+	// dedicated limits not listed in dedicatedEBSAttachmentsPerSize
+	defaultDedicatedEBSAttachments = 32
+)
+
+// DescribeInstanceTypes does not report the EBS volume attachment
+// limits in the aws-sdk-go version used, so the instance types whose
+// limits differ from the ones of their hypervisor are listed from
+// https://docs.aws.amazon.com/ec2/latest/instancetypes/ec2-instance-volume-limits.html
+// and need to be updated as instance types are released
+
+// dedicatedEBSAttachmentsPerType are the EBS volume attachment limits
+// of individual instance types, which are not shared with network
+// interfaces and instance store volumes
+var dedicatedEBSAttachmentsPerType = map[string]int64{
+	"d3.8xlarge":    3,
+	"d3en.12xlarge": 3,
+	"inf1.24xlarge": 11,
+	"mac1.metal":    16,
+	"mac2.metal":    16,
+	"u-6tb1.metal":  19,
+	"u-9tb1.metal":  19,
+	"u-12tb1.metal": 19,
+	"u-18tb1.metal": 19,
+	"u-24tb1.metal": 19,
+}
+
+// dedicatedEBSAttachmentFamilies are the instance families with a
+// dedicated EBS volume attachment limit depending on the instance
+// size, which is not shared with network interfaces and instance
+// store volumes
+var dedicatedEBSAttachmentFamilies = map[string]bool{
+	"c7a":      true,
+	"c7i":      true,
+	"c7i-flex": true,
+	"c8g":      true,
+	"i7ie":     true,
+	"m7a":      true,
+	"m7i":      true,
+	"m7i-flex": true,
+	"m8g":      true,
+	"r7a":      true,
+	"r7i":      true,
+	"r7iz":     true,
+	"r8g":      true,
+	"x8g":      true,
+}
+
+// dedicatedEBSAttachmentsPerSize are the dedicated EBS volume
+// attachment limits of the larger sizes of the instance families in
+// dedicatedEBSAttachmentFamilies
+var dedicatedEBSAttachmentsPerSize = map[string]int64{
+	"12xlarge":   40,
+	"16xlarge":   48,
+	"24xlarge":   64,
+	"32xlarge":   88,
+	"48xlarge":   128,
+	"metal-16xl": 48,
+	"metal-24xl": 64,
+	"metal-32xl": 88,
+	"metal-48xl": 128,
+}
+
+// dedicatedEBSAttachments returns the dedicated EBS volume attachment
+// limit of `instanceType` and whether it has one
+func dedicatedEBSAttachments(instanceType string) (int64, bool) {
+	if limit, ok := dedicatedEBSAttachmentsPerType[instanceType]; ok {
+		return limit, true
+	}
+
+	family, size, _ := strings.Cut(instanceType, ".")
+	if !dedicatedEBSAttachmentFamilies[family] {
+		return 0, false
+	}
+	if limit, ok := dedicatedEBSAttachmentsPerSize[size]; ok {
+		return limit, true
+	}
+	return defaultDedicatedEBSAttachments, true
+}
+
+// instanceTypeInfo holds the default limits of an instance type
+type instanceTypeInfo struct {
+	// vCPUs is the default number of vCPUs
+	vCPUs int64
+	// maxNetworkInterfaces is the maximum number of network
+	// interfaces
+	maxNetworkInterfaces int64
+	// ipv4AddressesPerInterface is the maximum number of IPv4
+	// addresses per network interface
+	ipv4AddressesPerInterface int64
+	// ipv6AddressesPerInterface is the maximum number of IPv6
+	// addresses per network interface
+	ipv6AddressesPerInterface int64
+	// maxEBSAttachments is the maximum number of EBS volume
+	// attachments
+	maxEBSAttachments int64
+	// sharedAttachments is true if the EBS volume attachments limit
+	// is shared with network interfaces and NVMe instance store
+	// volumes
+	sharedAttachments bool
+	// instanceStoreVolumes is the number of instance store volumes
+	instanceStoreVolumes int64
+}
+
+func newInstanceTypeInfo(instanceType *ec2.InstanceTypeInfo) instanceTypeInfo {
+	info := instanceTypeInfo{
+		maxEBSAttachments: xenMaxEBSAttachments,
+	}
+
+	if instanceType.VCpuInfo != nil {
+		info.vCPUs = aws.Int64Value(instanceType.VCpuInfo.DefaultVCpus)
+	}
+
+	if network := instanceType.NetworkInfo; network != nil {
+		info.maxNetworkInterfaces = aws.Int64Value(network.MaximumNetworkInterfaces)
+		info.ipv4AddressesPerInterface = aws.Int64Value(network.Ipv4AddressesPerInterface)
+		info.ipv6AddressesPerInterface = aws.Int64Value(network.Ipv6AddressesPerInterface)
+	}
+
+	if storage := instanceType.InstanceStorageInfo; storage != nil {
+		for _, disk := range storage.Disks {
+			info.instanceStoreVolumes += aws.Int64Value(disk.Count)
+		}
+	}
+
+	// Bare metal instances report no hypervisor but are built on the
+	// Nitro system
+	if aws.StringValue(instanceType.Hypervisor) == nitroHypervisor || aws.BoolValue(instanceType.BareMetal) {
+		info.maxEBSAttachments = nitroMaxAttachments
+		info.sharedAttachments = true
+	}
+
+	if limit, ok := dedicatedEBSAttachments(aws.StringValue(instanceType.InstanceType)); ok {
+		info.maxEBSAttachments = limit
+		info.sharedAttachments = false
+	}
+
+	return info
+}
+
+// instanceTypeCatalog caches the default limits of all instance types
+// offered in the region, for usage checks to fall back to when they
+// are not reported on the resources themselves
+type instanceTypeCatalog struct {
+	client        ec2iface.EC2API
+	ttl           time.Duration
+	lock          sync.Mutex
+	instanceTypes map[string]instanceTypeInfo
+	lastRefresh   time.Time
+}
+
+func newInstanceTypeCatalog(client ec2iface.EC2API) *instanceTypeCatalog {
+	return &instanceTypeCatalog{client: client, ttl: instanceTypeCatalogTTL}
+}
+
+// InstanceType returns the default limits of `instanceType` and
+// whether it is known, describing the instance types if the cached
+// ones are older than the catalog's TTL, or an error
+func (c *instanceTypeCatalog) InstanceType(instanceType string) (instanceTypeInfo, bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.instanceTypes == nil || time.Since(c.lastRefresh) >= c.ttl {
+		instanceTypes, err := c.describeInstanceTypes()
+		if err != nil {
+			return instanceTypeInfo{}, false, err
+		}
+
+		c.instanceTypes = instanceTypes
+		c.lastRefresh = time.Now()
+	}
+
+	info, ok := c.instanceTypes[instanceType]
+	return info, ok, nil
+}
+
+func (c *instanceTypeCatalog) describeInstanceTypes() (map[string]instanceTypeInfo, error) {
+	instanceTypes := map[string]instanceTypeInfo{}
+
+	params := &ec2.DescribeInstanceTypesInput{}
+	err := c.client.DescribeInstanceTypesPages(params,
+		func(page *ec2.DescribeInstanceTypesOutput, lastPage bool) bool {
+			if page != nil {
+				for _, instanceType := range page.InstanceTypes {
+					if instanceType.InstanceType != nil {
+						instanceTypes[*instanceType.InstanceType] = newInstanceTypeInfo(instanceType)
+					}
+				}
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return instanceTypes, nil
+}
@@ -0,0 +1,167 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func (m *mockEC2Client) DescribeInstanceTypesPages(input *ec2.DescribeInstanceTypesInput, fn func(*ec2.DescribeInstanceTypesOutput, bool) bool) error {
+	m.DescribeInstanceTypesCalls++
+	fn(m.DescribeInstanceTypesResponse, true)
+	return m.err
+}
+
+func TestInstanceTypeCatalogWithError(t *testing.T) {
+	mockClient := &mockEC2Client{err: errors.New("some err")}
+
+	info, known, err := newInstanceTypeCatalog(mockClient).InstanceType("m5.large")
+
+	assert.Error(t, err)
+	assert.False(t, known)
+	assert.Equal(t, instanceTypeInfo{}, info)
+}
+
+func TestInstanceTypeCatalog(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeInstanceTypesResponse: &ec2.DescribeInstanceTypesOutput{
+			InstanceTypes: []*ec2.InstanceTypeInfo{
+				{
+					InstanceType: aws.String("m5.large"),
+					Hypervisor:   aws.String("nitro"),
+					VCpuInfo:     &ec2.VCpuInfo{DefaultVCpus: aws.Int64(2)},
+					NetworkInfo: &ec2.NetworkInfo{
+						MaximumNetworkInterfaces:  aws.Int64(3),
+						Ipv4AddressesPerInterface: aws.Int64(10),
+						Ipv6AddressesPerInterface: aws.Int64(10),
+					},
+				},
+				{
+					InstanceType: aws.String("m4.large"),
+					Hypervisor:   aws.String("xen"),
+					VCpuInfo:     &ec2.VCpuInfo{DefaultVCpus: aws.Int64(2)},
+					NetworkInfo: &ec2.NetworkInfo{
+						MaximumNetworkInterfaces:  aws.Int64(2),
+						Ipv4AddressesPerInterface: aws.Int64(10),
+					},
+				},
+				{
+					InstanceType: aws.String("m5.metal"),
+				},
+				{
+					InstanceType: aws.String("m5zn.metal"),
+					BareMetal:    aws.Bool(true),
+				},
+				{
+					InstanceType: aws.String("m7i.large"),
+					Hypervisor:   aws.String("nitro"),
+				},
+				{
+					InstanceType: aws.String("m7i.48xlarge"),
+					Hypervisor:   aws.String("nitro"),
+				},
+				{
+					InstanceType: aws.String("u-12tb1.metal"),
+					BareMetal:    aws.Bool(true),
+				},
+				{
+					InstanceType: aws.String("m5d.2xlarge"),
+					Hypervisor:   aws.String("nitro"),
+					InstanceStorageInfo: &ec2.InstanceStorageInfo{
+						Disks: []*ec2.DiskInfo{{Count: aws.Int64(1), SizeInGB: aws.Int64(300)}},
+					},
+				},
+			},
+		},
+	}
+	catalog := newInstanceTypeCatalog(mockClient)
+
+	testCases := []struct {
+		instanceType  string
+		expectedInfo  instanceTypeInfo
+		expectedKnown bool
+	}{
+		{
+			instanceType: "m5.large",
+			expectedInfo: instanceTypeInfo{
+				vCPUs:                     2,
+				maxNetworkInterfaces:      3,
+				ipv4AddressesPerInterface: 10,
+				ipv6AddressesPerInterface: 10,
+				maxEBSAttachments:         28,
+				sharedAttachments:         true,
+			},
+			expectedKnown: true,
+		},
+		{
+			instanceType: "m4.large",
+			expectedInfo: instanceTypeInfo{
+				vCPUs:                     2,
+				maxNetworkInterfaces:      2,
+				ipv4AddressesPerInterface: 10,
+				maxEBSAttachments:         40,
+			},
+			expectedKnown: true,
+		},
+		{
+			instanceType:  "m5.metal",
+			expectedInfo:  instanceTypeInfo{maxEBSAttachments: 40},
+			expectedKnown: true,
+		},
+		{
+			instanceType:  "m5zn.metal",
+			expectedInfo:  instanceTypeInfo{maxEBSAttachments: 28, sharedAttachments: true},
+			expectedKnown: true,
+		},
+		{
+			instanceType:  "m7i.large",
+			expectedInfo:  instanceTypeInfo{maxEBSAttachments: 32},
+			expectedKnown: true,
+		},
+		{
+			instanceType:  "m7i.48xlarge",
+			expectedInfo:  instanceTypeInfo{maxEBSAttachments: 128},
+			expectedKnown: true,
+		},
+		{
+			instanceType:  "u-12tb1.metal",
+			expectedInfo:  instanceTypeInfo{maxEBSAttachments: 19},
+			expectedKnown: true,
+		},
+		{
+			instanceType: "m5d.2xlarge",
+			expectedInfo: instanceTypeInfo{
+				maxEBSAttachments:    28,
+				sharedAttachments:    true,
+				instanceStoreVolumes: 1,
+			},
+			expectedKnown: true,
+		},
+		{
+			instanceType:  "m9.large",
+			expectedInfo:  instanceTypeInfo{},
+			expectedKnown: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.instanceType, func(t *testing.T) {
+			info, known, err := catalog.InstanceType(tc.instanceType)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedKnown, known)
+			assert.Equal(t, tc.expectedInfo, info)
+		})
+	}
+	assert.Equal(t, 1, mockClient.DescribeInstanceTypesCalls)
+
+	catalog.lastRefresh = time.Now().Add(-instanceTypeCatalogTTL)
+	_, _, err := catalog.InstanceType("m5.large")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, mockClient.DescribeInstanceTypesCalls)
+}
@@ -0,0 +1,402 @@
+package servicequotas
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	logging "github.com/sirupsen/logrus"
+)
+
+const (
+	spotInstanceRequestsName = "spot_instance_requests"
+	spotInstanceRequestsDesc = "spot instance requests"
+
+	onDemandInstanceRequestsName = "ondemand_instance_requests"
+	onDemandInstanceRequestsDesc = "ondemand instance requests"
+
+	spotInstanceLifecycle = "spot"
+
+	instanceFamilyLabel = "instance_family"
+)
+
+// vCPUQuotaClass is a group of instance families sharing the same
+// On-Demand and Spot vCPU quotas
+// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/ec2-on-demand-instances.html#ec2-on-demand-instances-limits
+type vCPUQuotaClass struct {
+	// name is the name of the class in quota names, empty for the
+	// Standard class to keep the names of its quotas
+	name string
+	// description is the description of the class in quota
+	// descriptions, empty for the Standard class
+	description string
+	// onDemandQuotaCode is the code of the On-Demand vCPU quota
+	onDemandQuotaCode string
+	// spotQuotaCode is the code of the Spot vCPU quota, empty if
+	// the class can not be requested as Spot instances
+	spotQuotaCode string
+}
+
+// Instance types are mapped to their vCPU quota class by their
+// series, the letters their name starts with, in instanceSeries
+var (
+	standardVCPUQuotaClass   = vCPUQuotaClass{onDemandQuotaCode: "L-1216C47A", spotQuotaCode: "L-34B43A08"}
+	gVCPUQuotaClass          = vCPUQuotaClass{name: "g", description: "G and VT", onDemandQuotaCode: "L-DB2E81BA", spotQuotaCode: "L-3819A6DF"}
+	pVCPUQuotaClass          = vCPUQuotaClass{name: "p", description: "P", onDemandQuotaCode: "L-417A185B", spotQuotaCode: "L-7212CCBC"}
+	xVCPUQuotaClass          = vCPUQuotaClass{name: "x", description: "X", onDemandQuotaCode: "L-7295265B", spotQuotaCode: "L-E3A00192"}
+	fVCPUQuotaClass          = vCPUQuotaClass{name: "f", description: "F", onDemandQuotaCode: "L-74FC7D96", spotQuotaCode: "L-88CF9481"}
+	infVCPUQuotaClass        = vCPUQuotaClass{name: "inf", description: "Inf", onDemandQuotaCode: "L-1945791B", spotQuotaCode: "L-B5D1601B"}
+	trnVCPUQuotaClass        = vCPUQuotaClass{name: "trn", description: "Trn", onDemandQuotaCode: "L-2C3B7624", spotQuotaCode: "L-6B0D517C"}
+	dlVCPUQuotaClass         = vCPUQuotaClass{name: "dl", description: "DL", onDemandQuotaCode: "L-6E869C2A", spotQuotaCode: "L-85EED4F7"}
+	highMemoryVCPUQuotaClass = vCPUQuotaClass{name: "high_memory", description: "High Memory", onDemandQuotaCode: "L-43DA4232"}
+	hpcVCPUQuotaClass        = vCPUQuotaClass{name: "hpc", description: "HPC", onDemandQuotaCode: "L-F7808C92"}
+
+	vCPUQuotaClasses = []vCPUQuotaClass{
+		standardVCPUQuotaClass,
+		gVCPUQuotaClass,
+		pVCPUQuotaClass,
+		xVCPUQuotaClass,
+		fVCPUQuotaClass,
+		infVCPUQuotaClass,
+		trnVCPUQuotaClass,
+		dlVCPUQuotaClass,
+		highMemoryVCPUQuotaClass,
+		hpcVCPUQuotaClass,
+	}
+
+	// instanceSeries maps instance series to their vCPU quota class.
+	// Series with a nil class (Mac instances, which are limited by
+	// their dedicated hosts) are not counted against any vCPU quota
+	// https://docs.aws.amazon.com/ec2/latest/instancetypes/instance-type-names.html
+	instanceSeries = map[string]*vCPUQuotaClass{
+		"a":   &standardVCPUQuotaClass,
+		"c":   &standardVCPUQuotaClass,
+		"d":   &standardVCPUQuotaClass,
+		"h":   &standardVCPUQuotaClass,
+		"i":   &standardVCPUQuotaClass,
+		"im":  &standardVCPUQuotaClass,
+		"is":  &standardVCPUQuotaClass,
+		"m":   &standardVCPUQuotaClass,
+		"r":   &standardVCPUQuotaClass,
+		"t":   &standardVCPUQuotaClass,
+		"z":   &standardVCPUQuotaClass,
+		"g":   &gVCPUQuotaClass,
+		"gr":  &gVCPUQuotaClass,
+		"vt":  &gVCPUQuotaClass,
+		"p":   &pVCPUQuotaClass,
+		"x":   &xVCPUQuotaClass,
+		"f":   &fVCPUQuotaClass,
+		"inf": &infVCPUQuotaClass,
+		"trn": &trnVCPUQuotaClass,
+		"dl":  &dlVCPUQuotaClass,
+		"u":   &highMemoryVCPUQuotaClass,
+		"hpc": &hpcVCPUQuotaClass,
+		"mac": nil,
+	}
+)
+
+// instanceVCPUQuotaClass returns the vCPU quota class of
+// `instanceType` or false if it is not counted against any of the
+// covered vCPU quotas
+func instanceVCPUQuotaClass(instanceType string) (vCPUQuotaClass, bool) {
+	series := instanceType
+	if i := strings.IndexFunc(instanceType, func(r rune) bool { return r < 'a' || r > 'z' }); i >= 0 {
+		series = instanceType[:i]
+	}
+
+	class := instanceSeries[series]
+	if class == nil {
+		return vCPUQuotaClass{}, false
+	}
+	return *class, true
+}
+
+func (c vCPUQuotaClass) quotaName(spot bool) string {
+	switch {
+	case c.name == "" && spot:
+		return spotInstanceRequestsName
+	case c.name == "":
+		return onDemandInstanceRequestsName
+	case spot:
+		return fmt.Sprintf("spot_%s_instance_requests", c.name)
+	default:
+		return fmt.Sprintf("ondemand_%s_instance_requests", c.name)
+	}
+}
+
+func (c vCPUQuotaClass) quotaDescription(spot bool) string {
+	switch {
+	case c.description == "" && spot:
+		return spotInstanceRequestsDesc
+	case c.description == "":
+		return onDemandInstanceRequestsDesc
+	case spot:
+		return fmt.Sprintf("spot %s instance requests", c.description)
+	default:
+		return fmt.Sprintf("ondemand %s instance requests", c.description)
+	}
+}
+
+// instanceVCPUs returns the number of vCPUs of `instance` from its
+// CPU options, or false if they are unknown.
+// Note that we are working out the number of vCPUs for each instance
+// here because instances can have custom CPU options specified during
+// launch. More information can be found at
+// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/instance-optimize-cpu.html
+func instanceVCPUs(instance *ec2.Instance) (int64, bool) {
+	cpuOptions := instance.CpuOptions
+	if cpuOptions == nil || cpuOptions.CoreCount == nil || cpuOptions.ThreadsPerCore == nil {
+		return 0, false
+	}
+	return *cpuOptions.CoreCount * *cpuOptions.ThreadsPerCore, true
+}
+
+// isSpotInstance returns whether `instance` is a Spot instance.
+// InstanceLifecycle is nil for On-Demand instances. According to the
+// AWS docs it can also be "scheduled", those instances are neither
+// counted as On-Demand nor as Spot instances
+func isSpotInstance(instance *ec2.Instance) bool {
+	return instance.InstanceLifecycle != nil && *instance.InstanceLifecycle == spotInstanceLifecycle
+}
+
+// pendingAvailabilityZone is the availability zone of pending Spot
+// capacity in the vCPU usage breakdown, as it is not yet placed
+const pendingAvailabilityZone = "pending"
+
+// breakdownTagValuesSeparator separates the values of the breakdown
+// tags in vCPUsBreakdownKey
+const breakdownTagValuesSeparator = "\x00"
+
+// vCPUsBreakdownKey identifies a series of the vCPU usage breakdown
+type vCPUsBreakdownKey struct {
+	family           string
+	availabilityZone string
+	// tagValues are the values of the breakdown tags joined by
+	// breakdownTagValuesSeparator
+	tagValues string
+}
+
+// instanceFamily returns the family of `instanceType`, eg. "m5" for
+// "m5.large"
+func instanceFamily(instanceType string) string {
+	family, _, _ := strings.Cut(instanceType, ".")
+	return family
+}
+
+// InstanceVCPUsUsageCheck implements the UsageCheck interface for
+// the On-Demand or Spot vCPUs of a vCPU quota class
+type InstanceVCPUsUsageCheck struct {
+	instances     *instancesCache
+	instanceTypes *instanceTypeCatalog
+	// pendingSpot is only used by Spot checks
+	pendingSpot *pendingSpotCache
+	class       vCPUQuotaClass
+	spot        bool
+	// breakdown additionally reports the vCPU usage per instance
+	// family, availability zone and the instance tags in
+	// breakdownTags
+	breakdown     bool
+	breakdownTags []string
+}
+
+// Usage returns the vCPU usage of all running On-Demand or Spot
+// instances of the check's vCPU quota class or an error. vCPUs are
+// returned instead of the number of instances due to the service
+// quota reporting the number of vCPUs. The default vCPUs of the
+// instance type are used for instances without CPU options. Spot
+// usage also includes open Spot requests and unfulfilled Spot fleet
+// capacity
+// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/using-spot-limits.html
+func (c *InstanceVCPUsUsageCheck) Usage() ([]QuotaUsage, error) {
+	instances, err := c.instances.Instances()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	var totalvCPUs float64
+	breakdownvCPUs := map[vCPUsBreakdownKey]float64{}
+	for _, instance := range instances {
+		if instance.InstanceType == nil || isSpotInstance(instance) != c.spot {
+			continue
+		}
+		if !c.spot && instance.InstanceLifecycle != nil {
+			continue
+		}
+
+		class, ok := instanceVCPUQuotaClass(*instance.InstanceType)
+		if !ok || class != c.class {
+			continue
+		}
+
+		vCPUs, ok := instanceVCPUs(instance)
+		if !ok {
+			info, known, err := c.instanceTypes.InstanceType(*instance.InstanceType)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+			}
+			if !known {
+				logging.Warnf("Unknown instance type (%s), not counting the vCPUs of instance (%s)",
+					*instance.InstanceType, aws.StringValue(instance.InstanceId))
+			}
+			vCPUs = info.vCPUs
+		}
+		totalvCPUs += float64(vCPUs)
+
+		if c.breakdown {
+			key := vCPUsBreakdownKey{
+				family:    instanceFamily(*instance.InstanceType),
+				tagValues: c.breakdownTagValues(instance.Tags),
+			}
+			if instance.Placement != nil {
+				key.availabilityZone = aws.StringValue(instance.Placement.AvailabilityZone)
+			}
+			breakdownvCPUs[key] += float64(vCPUs)
+		}
+	}
+
+	if c.spot {
+		pendingvCPUs, err := c.pendingSpot.VCPUs()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+		}
+
+		for instanceType, vCPUs := range pendingvCPUs {
+			if class, ok := instanceVCPUQuotaClass(instanceType); !ok || class != c.class {
+				continue
+			}
+			totalvCPUs += vCPUs
+
+			if c.breakdown {
+				key := vCPUsBreakdownKey{
+					family:           instanceFamily(instanceType),
+					availabilityZone: pendingAvailabilityZone,
+					tagValues:        c.breakdownTagValues(nil),
+				}
+				breakdownvCPUs[key] += vCPUs
+			}
+		}
+	}
+
+	usage := []QuotaUsage{
+		{
+			Name:        c.class.quotaName(c.spot),
+			Description: c.class.quotaDescription(c.spot),
+			Usage:       totalvCPUs,
+		},
+	}
+
+	if c.breakdown {
+		usage = append(usage, c.breakdownUsage(breakdownvCPUs)...)
+	}
+	return usage, nil
+}
+
+func (c *InstanceVCPUsUsageCheck) breakdownTagValues(tags []*ec2.Tag) string {
+	instanceTags := map[string]string{}
+	for _, tag := range tags {
+		instanceTags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	values := make([]string, 0, len(c.breakdownTags))
+	for _, tag := range c.breakdownTags {
+		values = append(values, instanceTags[tag])
+	}
+	return strings.Join(values, breakdownTagValuesSeparator)
+}
+
+// breakdownSeries returns the resource name and labels of the vCPU
+// usage breakdown series identified by `key`
+func (c *InstanceVCPUsUsageCheck) breakdownSeries(key vCPUsBreakdownKey) (string, map[string]string) {
+	parts := []string{key.family, key.availabilityZone}
+	labels := map[string]string{
+		instanceFamilyLabel:   key.family,
+		availabilityZoneLabel: key.availabilityZone,
+	}
+
+	if len(c.breakdownTags) > 0 {
+		tagValues := strings.Split(key.tagValues, breakdownTagValuesSeparator)
+		for i, tag := range c.breakdownTags {
+			parts = append(parts, tagValues[i])
+			labels[breakdownTagLabel(tag)] = tagValues[i]
+		}
+	}
+
+	return strings.Join(parts, "/"), labels
+}
+
+// breakdownUsage returns the vCPU usage breakdown series sorted by
+// their resource name
+func (c *InstanceVCPUsUsageCheck) breakdownUsage(breakdownvCPUs map[vCPUsBreakdownKey]float64) []QuotaUsage {
+	usage := make([]QuotaUsage, 0, len(breakdownvCPUs))
+	for key, vCPUs := range breakdownvCPUs {
+		resourceName, labels := c.breakdownSeries(key)
+		usage = append(usage, QuotaUsage{
+			Name:         c.breakdownName(),
+			ResourceName: &resourceName,
+			Description:  c.breakdownDescription(),
+			Usage:        vCPUs,
+			Labels:       labels,
+		})
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		return *usage[i].ResourceName < *usage[j].ResourceName
+	})
+	return usage
+}
+
+func (c *InstanceVCPUsUsageCheck) breakdownName() string {
+	return fmt.Sprintf("%s_breakdown", c.class.quotaName(c.spot))
+}
+
+func (c *InstanceVCPUsUsageCheck) breakdownDescription() string {
+	return fmt.Sprintf("%s by instance family and availability zone", c.class.quotaDescription(c.spot))
+}
+
+// breakdownTagLabel returns the label of the instance tag `tag` in the
+// vCPU usage breakdown. Tags are prefixed to not clash with the
+// labels of tags included with all quotas
+func breakdownTagLabel(tag string) string {
+	return fmt.Sprintf("tag_%s", ToPrometheusNamingFormat(tag))
+}
+
+// Definitions returns the On-Demand or Spot vCPU quota of the check's
+// vCPU quota class and its breakdown if enabled
+func (c *InstanceVCPUsUsageCheck) Definitions() []QuotaDefinition {
+	definitions := []QuotaDefinition{{Name: c.class.quotaName(c.spot), Description: c.class.quotaDescription(c.spot)}}
+
+	if c.breakdown {
+		definitions = append(definitions, QuotaDefinition{Name: c.breakdownName(), Description: c.breakdownDescription(), Breakdown: true})
+	}
+	return definitions
+}
+
+// instanceVCPUsUsageChecks returns the On-Demand and Spot vCPU usage
+// checks of all vCPU quota classes by quota code, all sharing
+// `instances`, `instanceTypes` and `pendingSpot`
+func instanceVCPUsUsageChecks(options Options, instances *instancesCache, instanceTypes *instanceTypeCatalog, pendingSpot *pendingSpotCache) map[string]UsageCheck {
+	checks := map[string]UsageCheck{}
+	for _, class := range vCPUQuotaClasses {
+		checks[class.onDemandQuotaCode] = &InstanceVCPUsUsageCheck{
+			instances:     instances,
+			instanceTypes: instanceTypes,
+			class:         class,
+			breakdown:     options.VCPUBreakdown,
+			breakdownTags: options.VCPUBreakdownTags,
+		}
+		if class.spotQuotaCode != "" {
+			checks[class.spotQuotaCode] = &InstanceVCPUsUsageCheck{
+				instances:     instances,
+				instanceTypes: instanceTypes,
+				pendingSpot:   pendingSpot,
+				class:         class,
+				spot:          true,
+				breakdown:     options.VCPUBreakdown,
+				breakdownTags: options.VCPUBreakdownTags,
+			}
+		}
+	}
+	return checks
+}
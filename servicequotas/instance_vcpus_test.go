@@ -0,0 +1,292 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func testInstance(instanceType string, lifecycle *string, coreCount, threadsPerCore int64) *ec2.Instance {
+	return &ec2.Instance{
+		InstanceType:      aws.String(instanceType),
+		InstanceLifecycle: lifecycle,
+		CpuOptions: &ec2.CpuOptions{
+			CoreCount:      aws.Int64(coreCount),
+			ThreadsPerCore: aws.Int64(threadsPerCore),
+		},
+	}
+}
+
+func TestInstanceVCPUQuotaClass(t *testing.T) {
+	testCases := []struct {
+		instanceType  string
+		expectedClass vCPUQuotaClass
+		expectedOK    bool
+	}{
+		{instanceType: "m5.large", expectedClass: standardVCPUQuotaClass, expectedOK: true},
+		{instanceType: "im4gn.large", expectedClass: standardVCPUQuotaClass, expectedOK: true},
+		{instanceType: "d3.xlarge", expectedClass: standardVCPUQuotaClass, expectedOK: true},
+		{instanceType: "g5.xlarge", expectedClass: gVCPUQuotaClass, expectedOK: true},
+		{instanceType: "vt1.3xlarge", expectedClass: gVCPUQuotaClass, expectedOK: true},
+		{instanceType: "p4d.24xlarge", expectedClass: pVCPUQuotaClass, expectedOK: true},
+		{instanceType: "x2idn.16xlarge", expectedClass: xVCPUQuotaClass, expectedOK: true},
+		{instanceType: "f1.2xlarge", expectedClass: fVCPUQuotaClass, expectedOK: true},
+		{instanceType: "inf2.xlarge", expectedClass: infVCPUQuotaClass, expectedOK: true},
+		{instanceType: "trn1.2xlarge", expectedClass: trnVCPUQuotaClass, expectedOK: true},
+		{instanceType: "dl1.24xlarge", expectedClass: dlVCPUQuotaClass, expectedOK: true},
+		{instanceType: "u-6tb1.metal", expectedClass: highMemoryVCPUQuotaClass, expectedOK: true},
+		{instanceType: "hpc6a.48xlarge", expectedClass: hpcVCPUQuotaClass, expectedOK: true},
+		{instanceType: "mac1.metal", expectedOK: false},
+		{instanceType: "unknown.large", expectedOK: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.instanceType, func(t *testing.T) {
+			class, ok := instanceVCPUQuotaClass(tc.instanceType)
+
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedClass, class)
+		})
+	}
+}
+
+func TestInstanceVCPUsUsageWithError(t *testing.T) {
+	mockClient := &mockEC2Client{err: errors.New("some err")}
+
+	check := InstanceVCPUsUsageCheck{instances: newInstancesCache(mockClient), class: standardVCPUQuotaClass}
+	usage, err := check.Usage()
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+	assert.Nil(t, usage)
+}
+
+func TestInstanceVCPUsUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeInstancesResponse: &ec2.DescribeInstancesOutput{
+			Reservations: []*ec2.Reservation{
+				{
+					Instances: []*ec2.Instance{
+						testInstance("m5.2xlarge", aws.String("spot"), 4, 2),
+						testInstance("g5.xlarge", aws.String("spot"), 2, 2),
+					},
+				},
+				{
+					Instances: []*ec2.Instance{
+						testInstance("m5.large", nil, 1, 2),
+						testInstance("c5.2xlarge", nil, 4, 2),
+						testInstance("r5.large", aws.String("scheduled"), 1, 2),
+						testInstance("p3.2xlarge", nil, 4, 2),
+						testInstance("u-6tb1.metal", nil, 224, 2),
+						testInstance("hpc6a.48xlarge", nil, 96, 1),
+						{InstanceType: aws.String("t3.micro"), CpuOptions: &ec2.CpuOptions{}},
+						{InstanceType: aws.String("m9.large")},
+					},
+				},
+			},
+		},
+		DescribeInstanceTypesResponse: &ec2.DescribeInstanceTypesOutput{
+			InstanceTypes: []*ec2.InstanceTypeInfo{
+				{InstanceType: aws.String("t3.micro"), VCpuInfo: &ec2.VCpuInfo{DefaultVCpus: aws.Int64(2)}},
+				{InstanceType: aws.String("m5.large"), VCpuInfo: &ec2.VCpuInfo{DefaultVCpus: aws.Int64(2)}},
+			},
+		},
+		DescribeSpotInstanceRequestsResponse: &ec2.DescribeSpotInstanceRequestsOutput{
+			SpotInstanceRequests: []*ec2.SpotInstanceRequest{
+				{
+					State:               aws.String("open"),
+					LaunchSpecification: &ec2.LaunchSpecification{InstanceType: aws.String("m5.large")},
+				},
+			},
+		},
+	}
+	instances := newInstancesCache(mockClient)
+	instanceTypes := newInstanceTypeCatalog(mockClient)
+	pendingSpot := newPendingSpotCache(mockClient, instanceTypes)
+
+	testCases := []struct {
+		name          string
+		class         vCPUQuotaClass
+		spot          bool
+		expectedUsage []QuotaUsage
+	}{
+		{
+			name:  "OnDemandStandard",
+			class: standardVCPUQuotaClass,
+			expectedUsage: []QuotaUsage{
+				{Name: onDemandInstanceRequestsName, Description: onDemandInstanceRequestsDesc, Usage: 12},
+			},
+		},
+		{
+			name:  "SpotStandard",
+			class: standardVCPUQuotaClass,
+			spot:  true,
+			expectedUsage: []QuotaUsage{
+				{Name: spotInstanceRequestsName, Description: spotInstanceRequestsDesc, Usage: 10},
+			},
+		},
+		{
+			name:  "OnDemandG",
+			class: gVCPUQuotaClass,
+			expectedUsage: []QuotaUsage{
+				{Name: "ondemand_g_instance_requests", Description: "ondemand G and VT instance requests", Usage: 0},
+			},
+		},
+		{
+			name:  "SpotG",
+			class: gVCPUQuotaClass,
+			spot:  true,
+			expectedUsage: []QuotaUsage{
+				{Name: "spot_g_instance_requests", Description: "spot G and VT instance requests", Usage: 4},
+			},
+		},
+		{
+			name:  "OnDemandP",
+			class: pVCPUQuotaClass,
+			expectedUsage: []QuotaUsage{
+				{Name: "ondemand_p_instance_requests", Description: "ondemand P instance requests", Usage: 8},
+			},
+		},
+		{
+			name:  "OnDemandHighMemory",
+			class: highMemoryVCPUQuotaClass,
+			expectedUsage: []QuotaUsage{
+				{Name: "ondemand_high_memory_instance_requests", Description: "ondemand High Memory instance requests", Usage: 448},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := InstanceVCPUsUsageCheck{instances: instances, instanceTypes: instanceTypes, pendingSpot: pendingSpot, class: tc.class, spot: tc.spot}
+			usage, err := check.Usage()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedUsage, usage)
+		})
+	}
+	assert.Equal(t, 1, mockClient.DescribeInstancesCalls)
+	assert.Equal(t, 1, mockClient.DescribeInstanceTypesCalls)
+}
+
+func TestInstanceVCPUsUsageWithInstanceTypesError(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeInstancesResponse: &ec2.DescribeInstancesOutput{
+			Reservations: []*ec2.Reservation{
+				{Instances: []*ec2.Instance{{InstanceType: aws.String("t3.micro")}}},
+			},
+		},
+	}
+	instances := newInstancesCache(mockClient)
+	_, err := instances.Instances()
+	assert.NoError(t, err)
+
+	mockClient.err = errors.New("some err")
+	check := InstanceVCPUsUsageCheck{instances: instances, instanceTypes: newInstanceTypeCatalog(mockClient), class: standardVCPUQuotaClass}
+	usage, err := check.Usage()
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+	assert.Nil(t, usage)
+}
+
+func TestInstanceVCPUsUsageChecks(t *testing.T) {
+	checks := instanceVCPUsUsageChecks(Options{}, newInstancesCache(&mockEC2Client{}), newInstanceTypeCatalog(&mockEC2Client{}), newPendingSpotCache(&mockEC2Client{}, nil))
+
+	assert.Len(t, checks, 18)
+	assert.Equal(t, []QuotaDefinition{{Name: onDemandInstanceRequestsName, Description: onDemandInstanceRequestsDesc}}, checks["L-1216C47A"].Definitions())
+	assert.Equal(t, []QuotaDefinition{{Name: spotInstanceRequestsName, Description: spotInstanceRequestsDesc}}, checks["L-34B43A08"].Definitions())
+	assert.Equal(t, []QuotaDefinition{{Name: "ondemand_hpc_instance_requests", Description: "ondemand HPC instance requests"}}, checks["L-F7808C92"].Definitions())
+	assert.NotContains(t, checks, "")
+}
+
+func TestInstanceVCPUsUsageWithBreakdown(t *testing.T) {
+	placedInstance := func(instanceType, availabilityZone, team string, coreCount int64) *ec2.Instance {
+		instance := testInstance(instanceType, aws.String("spot"), coreCount, 2)
+		instance.Placement = &ec2.Placement{AvailabilityZone: aws.String(availabilityZone)}
+		if team != "" {
+			instance.Tags = []*ec2.Tag{{Key: aws.String("team"), Value: aws.String(team)}}
+		}
+		return instance
+	}
+
+	mockClient := &mockEC2Client{
+		DescribeInstancesResponse: &ec2.DescribeInstancesOutput{
+			Reservations: []*ec2.Reservation{
+				{
+					Instances: []*ec2.Instance{
+						placedInstance("m5.large", "eu-west-1a", "a", 1),
+						placedInstance("m5.xlarge", "eu-west-1a", "a", 2),
+						placedInstance("m5.large", "eu-west-1b", "b", 1),
+						placedInstance("c5.large", "eu-west-1a", "", 1),
+					},
+				},
+			},
+		},
+		DescribeInstanceTypesResponse: testInstanceTypes(),
+		DescribeSpotInstanceRequestsResponse: &ec2.DescribeSpotInstanceRequestsOutput{
+			SpotInstanceRequests: []*ec2.SpotInstanceRequest{
+				{
+					State:               aws.String("open"),
+					LaunchSpecification: &ec2.LaunchSpecification{InstanceType: aws.String("c5.4xlarge")},
+				},
+			},
+		},
+	}
+	instanceTypes := newInstanceTypeCatalog(mockClient)
+
+	check := InstanceVCPUsUsageCheck{
+		instances:     newInstancesCache(mockClient),
+		instanceTypes: instanceTypes,
+		pendingSpot:   newPendingSpotCache(mockClient, instanceTypes),
+		class:         standardVCPUQuotaClass,
+		spot:          true,
+		breakdown:     true,
+		breakdownTags: []string{"team"},
+	}
+	usage, err := check.Usage()
+
+	breakdownName := "spot_instance_requests_breakdown"
+	breakdownDesc := "spot instance requests by instance family and availability zone"
+	expectedUsage := []QuotaUsage{
+		{Name: spotInstanceRequestsName, Description: spotInstanceRequestsDesc, Usage: 26},
+		{
+			Name:         breakdownName,
+			ResourceName: aws.String("c5/eu-west-1a/"),
+			Description:  breakdownDesc,
+			Usage:        2,
+			Labels:       map[string]string{"instance_family": "c5", "availability_zone": "eu-west-1a", "tag_team": ""},
+		},
+		{
+			Name:         breakdownName,
+			ResourceName: aws.String("c5/pending/"),
+			Description:  breakdownDesc,
+			Usage:        16,
+			Labels:       map[string]string{"instance_family": "c5", "availability_zone": "pending", "tag_team": ""},
+		},
+		{
+			Name:         breakdownName,
+			ResourceName: aws.String("m5/eu-west-1a/a"),
+			Description:  breakdownDesc,
+			Usage:        6,
+			Labels:       map[string]string{"instance_family": "m5", "availability_zone": "eu-west-1a", "tag_team": "a"},
+		},
+		{
+			Name:         breakdownName,
+			ResourceName: aws.String("m5/eu-west-1b/b"),
+			Description:  breakdownDesc,
+			Usage:        2,
+			Labels:       map[string]string{"instance_family": "m5", "availability_zone": "eu-west-1b", "tag_team": "b"},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+	assert.Equal(t, []QuotaDefinition{
+		{Name: spotInstanceRequestsName, Description: spotInstanceRequestsDesc},
+		{Name: breakdownName, Description: breakdownDesc, Breakdown: true},
+	}, check.Definitions())
+}
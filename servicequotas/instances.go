@@ -0,0 +1,71 @@
+package servicequotas
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// instancesCacheTTL is how long the instances are cached for. It is
+// shorter than any sensible refresh period, so that all usage checks
+// of a refresh share a single DescribeInstances pass
+const instancesCacheTTL = time.Minute
+
+func activeInstanceFilter() *ec2.Filter {
+	return &ec2.Filter{
+		Name: aws.String("instance-state-name"),
+		Values: []*string{
+			aws.String("pending"),
+			aws.String("running"),
+		},
+	}
+}
+
+// instancesCache caches the active (pending and running) EC2
+// instances so usage checks based on instances don't describe them
+// separately
+type instancesCache struct {
+	client      ec2iface.EC2API
+	ttl         time.Duration
+	lock        sync.Mutex
+	instances   []*ec2.Instance
+	lastRefresh time.Time
+}
+
+func newInstancesCache(client ec2iface.EC2API) *instancesCache {
+	return &instancesCache{client: client, ttl: instancesCacheTTL}
+}
+
+// Instances returns the active EC2 instances, describing them if the
+// cached instances are older than the cache's TTL, or an error
+func (c *instancesCache) Instances() ([]*ec2.Instance, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.instances != nil && time.Since(c.lastRefresh) < c.ttl {
+		return c.instances, nil
+	}
+
+	instances := []*ec2.Instance{}
+	params := &ec2.DescribeInstancesInput{Filters: []*ec2.Filter{activeInstanceFilter()}}
+	err := c.client.DescribeInstancesPages(params,
+		func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+			if page != nil {
+				for _, reservation := range page.Reservations {
+					instances = append(instances, reservation.Instances...)
+				}
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	c.instances = instances
+	c.lastRefresh = time.Now()
+	return instances, nil
+}
@@ -0,0 +1,47 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstancesCacheWithError(t *testing.T) {
+	mockClient := &mockEC2Client{err: errors.New("some err")}
+
+	instances, err := newInstancesCache(mockClient).Instances()
+
+	assert.Error(t, err)
+	assert.Nil(t, instances)
+}
+
+func TestInstancesCache(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeInstancesResponse: &ec2.DescribeInstancesOutput{
+			Reservations: []*ec2.Reservation{
+				{Instances: []*ec2.Instance{{InstanceId: aws.String("i-1")}}},
+				{Instances: []*ec2.Instance{{InstanceId: aws.String("i-2")}, {InstanceId: aws.String("i-3")}}},
+			},
+		},
+	}
+	cache := newInstancesCache(mockClient)
+
+	for i := 0; i < 3; i++ {
+		instances, err := cache.Instances()
+
+		assert.NoError(t, err)
+		assert.Len(t, instances, 3)
+	}
+	assert.Equal(t, 1, mockClient.DescribeInstancesCalls)
+	assert.Equal(t, []*ec2.Filter{activeInstanceFilter()}, mockClient.InstancesFilters)
+
+	cache.lastRefresh = time.Now().Add(-instancesCacheTTL)
+	_, err := cache.Instances()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, mockClient.DescribeInstancesCalls)
+}
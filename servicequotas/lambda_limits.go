@@ -47,3 +47,12 @@ func (c *LambdaConcurrentExecutionsLimitCheck) Usage() ([]QuotaUsage, error) {
 
 	return usages, err
 }
+
+// Definitions returns the lambda concurrent executions and code size
+// unzipped limits
+func (c *LambdaConcurrentExecutionsLimitCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{
+		{Name: lambdaConcurrentExecutionsLimitName, Description: lambdaConcurrentExecutionsLimitDesc},
+		{Name: lambdaCodeSizeUnzippedLimitBytesName, Description: lambdaCodeSizeUnzippedLimitBytesDesc},
+	}
+}
@@ -8,10 +8,49 @@ import (
 type mockEC2Client struct {
 	ec2iface.EC2API
 
-	err                               error
-	DescribeSecurityGroupsResponse    *ec2.DescribeSecurityGroupsOutput
-	DescribeNetworkInterfacesResponse *ec2.DescribeNetworkInterfacesOutput
-	InstancesFilters                  []*ec2.Filter
-	DescribeInstancesResponse         *ec2.DescribeInstancesOutput
-	DescribeSubnetsResponse           *ec2.DescribeSubnetsOutput
+	err                                        error
+	DescribeSecurityGroupsResponse             *ec2.DescribeSecurityGroupsOutput
+	DescribeSecurityGroupsCalls                int
+	DescribeNetworkInterfacesCalls             int
+	DescribeNetworkInterfacesResponse          *ec2.DescribeNetworkInterfacesOutput
+	InstancesFilters                           []*ec2.Filter
+	DescribeInstancesCalls                     int
+	DescribeInstanceTypesResponse              *ec2.DescribeInstanceTypesOutput
+	DescribeInstanceTypesCalls                 int
+	DescribeSpotInstanceRequestsResponse       *ec2.DescribeSpotInstanceRequestsOutput
+	DescribeSpotFleetRequestsResponse          *ec2.DescribeSpotFleetRequestsOutput
+	DescribeFleetsResponse                     *ec2.DescribeFleetsOutput
+	DescribeInstancesResponse                  *ec2.DescribeInstancesOutput
+	DescribeSubnetsResponse                    *ec2.DescribeSubnetsOutput
+	DescribeSubnetsCalls                       int
+	DescribeVpcsResponse                       *ec2.DescribeVpcsOutput
+	DescribeVpcsCalls                          int
+	DescribeManagedPrefixListsResponse         *ec2.DescribeManagedPrefixListsOutput
+	DescribeManagedPrefixListsCalls            int
+	DescribeInternetGatewaysResponse           *ec2.DescribeInternetGatewaysOutput
+	DescribeNatGatewaysResponse                *ec2.DescribeNatGatewaysOutput
+	NatGatewaysFilters                         []*ec2.Filter
+	DescribeEgressOnlyInternetGatewaysResponse *ec2.DescribeEgressOnlyInternetGatewaysOutput
+	DescribeRouteTablesResponse                *ec2.DescribeRouteTablesOutput
+	DescribeRouteTablesCalls                   int
+	DescribeNetworkAclsResponse                *ec2.DescribeNetworkAclsOutput
+	DescribeVpcPeeringConnectionsResponse      *ec2.DescribeVpcPeeringConnectionsOutput
+	VpcPeeringConnectionsFilters               []*ec2.Filter
+	DescribeVpcEndpointsResponse               *ec2.DescribeVpcEndpointsOutput
+	GetManagedPrefixListEntriesResponses       map[string]*ec2.GetManagedPrefixListEntriesOutput
+	DescribeTransitGatewaysResponse            *ec2.DescribeTransitGatewaysOutput
+	DescribeTransitGatewayAttachmentsResponse  *ec2.DescribeTransitGatewayAttachmentsOutput
+	DescribeTransitGatewayRouteTablesResponse  *ec2.DescribeTransitGatewayRouteTablesOutput
+	TransitGatewayRoutes                       map[string][]*ec2.TransitGatewayRoute
+	TransitGatewayPrefixListReferences         map[string][]*ec2.TransitGatewayPrefixListReference
+	DescribeVolumesResponse                    *ec2.DescribeVolumesOutput
+	DescribeVolumesCalls                       int
+	DescribeSnapshotsResponse                  *ec2.DescribeSnapshotsOutput
+	SnapshotsOwnerIds                          []*string
+	DescribeImagesResponse                     *ec2.DescribeImagesOutput
+	ImagesInput                                *ec2.DescribeImagesInput
+	DescribeLaunchTemplatesResponse            *ec2.DescribeLaunchTemplatesOutput
+	DescribeKeyPairsResponse                   *ec2.DescribeKeyPairsOutput
+	DescribeHostsResponse                      *ec2.DescribeHostsOutput
+	DescribeAddressesResponse                  *ec2.DescribeAddressesOutput
 }
@@ -0,0 +1,29 @@
+package servicequotas
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+)
+
+type mockSTSClient struct {
+	stsiface.STSAPI
+
+	err                    error
+	accountID              string
+	GetCallerIdentityCalls int
+}
+
+func (m *mockSTSClient) GetCallerIdentity(input *sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+	m.GetCallerIdentityCalls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &sts.GetCallerIdentityOutput{Account: aws.String(m.accountID)}, nil
+}
+
+// testAccountID returns an account ID cache for the account
+// 123456789012 owning the test resources
+func testAccountID() *accountIDCache {
+	return newAccountIDCache(&mockSTSClient{accountID: "123456789012"})
+}
@@ -0,0 +1,170 @@
+package servicequotas
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+const (
+	networkACLsPerVPCName = "network_acls_per_vpc"
+	networkACLsPerVPCDesc = "network ACLs per VPC"
+
+	inboundRulesPerNetworkACLName = "inbound_rules_per_network_acl"
+	inboundRulesPerNetworkACLDesc = "inbound rules per network ACL"
+
+	outboundRulesPerNetworkACLName = "outbound_rules_per_network_acl"
+	outboundRulesPerNetworkACLDesc = "outbound rules per network ACL"
+
+	// defaultNetworkACLRuleNumber is the rule number of the default
+	// IPv4 deny rules every network ACL has, followed by the one of
+	// the default IPv6 deny rules. They can not be removed and do not
+	// count against the rules per network ACL quota
+	defaultNetworkACLRuleNumber = 32767
+)
+
+// NetworkACLsPerVPCUsageCheck implements the UsageCheck interface for
+// network ACLs per VPC
+type NetworkACLsPerVPCUsageCheck struct {
+	client ec2iface.EC2API
+	vpcs   *vpcsCache
+}
+
+// Usage returns usage for each VPC ID with the usage value being the
+// number of network ACLs in the VPC, including its default network
+// ACL, or an error
+func (c *NetworkACLsPerVPCUsageCheck) Usage() ([]QuotaUsage, error) {
+	vpcs, err := c.vpcs.OwnedVPCs()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	networkACLs, err := describeNetworkACLs(c.client)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	networkACLsPerVPC := map[string]int{}
+	for _, networkACL := range networkACLs {
+		networkACLsPerVPC[aws.StringValue(networkACL.VpcId)]++
+	}
+
+	return vpcCountUsages(networkACLsPerVPCName, networkACLsPerVPCDesc, vpcs, networkACLsPerVPC), nil
+}
+
+// Definitions returns the network ACLs per VPC quota
+func (c *NetworkACLsPerVPCUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: networkACLsPerVPCName, Description: networkACLsPerVPCDesc}}
+}
+
+// RulesPerNetworkACLUsageCheck implements the UsageCheck interface
+// for rules per network ACL
+type RulesPerNetworkACLUsageCheck struct {
+	client ec2iface.EC2API
+}
+
+// networkACLRuleCount is the number of IPv4 and IPv6 rules of a
+// network ACL in one direction
+type networkACLRuleCount struct {
+	ipv4 int
+	ipv6 int
+}
+
+// max returns the larger of the IPv4 and IPv6 rule counts, as the
+// quota applies to each address family separately
+func (c networkACLRuleCount) max() int {
+	if c.ipv6 > c.ipv4 {
+		return c.ipv6
+	}
+	return c.ipv4
+}
+
+// Usage returns the usage for each network ACL ID with the usage
+// value being the number of their inbound and outbound rules,
+// excluding the default deny rules, or an error. IPv4 and IPv6 rules
+// count against separate quotas, so the usage is the larger of the
+// two per direction
+func (c *RulesPerNetworkACLUsageCheck) Usage() ([]QuotaUsage, error) {
+	networkACLs, err := describeNetworkACLs(c.client)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	quotaUsages := []QuotaUsage{}
+	for _, networkACL := range networkACLs {
+		if networkACL.NetworkAclId == nil {
+			continue
+		}
+
+		inboundRules := networkACLRuleCount{}
+		outboundRules := networkACLRuleCount{}
+		for _, entry := range networkACL.Entries {
+			if aws.Int64Value(entry.RuleNumber) >= defaultNetworkACLRuleNumber {
+				continue
+			}
+
+			rules := &inboundRules
+			if aws.BoolValue(entry.Egress) {
+				rules = &outboundRules
+			}
+
+			if entry.Ipv6CidrBlock != nil {
+				rules.ipv6++
+			} else {
+				rules.ipv4++
+			}
+		}
+
+		tags := ec2TagsToQuotaUsageTags(networkACL.Tags)
+		labels := map[string]string{vpcIDLabel: aws.StringValue(networkACL.VpcId)}
+
+		inboundUsage := QuotaUsage{
+			Name:         inboundRulesPerNetworkACLName,
+			ResourceName: networkACL.NetworkAclId,
+			Description:  inboundRulesPerNetworkACLDesc,
+			Usage:        float64(inboundRules.max()),
+			Tags:         tags,
+			Labels:       labels,
+		}
+
+		outboundUsage := QuotaUsage{
+			Name:         outboundRulesPerNetworkACLName,
+			ResourceName: networkACL.NetworkAclId,
+			Description:  outboundRulesPerNetworkACLDesc,
+			Usage:        float64(outboundRules.max()),
+			Tags:         tags,
+			Labels:       labels,
+		}
+
+		quotaUsages = append(quotaUsages, []QuotaUsage{inboundUsage, outboundUsage}...)
+	}
+
+	return quotaUsages, nil
+}
+
+// Definitions returns the inbound and outbound rules per network ACL
+// quotas
+func (c *RulesPerNetworkACLUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{
+		{Name: inboundRulesPerNetworkACLName, Description: inboundRulesPerNetworkACLDesc},
+		{Name: outboundRulesPerNetworkACLName, Description: outboundRulesPerNetworkACLDesc},
+	}
+}
+
+func describeNetworkACLs(client ec2iface.EC2API) ([]*ec2.NetworkAcl, error) {
+	networkACLs := []*ec2.NetworkAcl{}
+	err := client.DescribeNetworkAclsPages(&ec2.DescribeNetworkAclsInput{},
+		func(page *ec2.DescribeNetworkAclsOutput, lastPage bool) bool {
+			if page != nil {
+				networkACLs = append(networkACLs, page.NetworkAcls...)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return networkACLs, nil
+}
@@ -0,0 +1,199 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func (m *mockEC2Client) DescribeNetworkAclsPages(input *ec2.DescribeNetworkAclsInput, fn func(*ec2.DescribeNetworkAclsOutput, bool) bool) error {
+	fn(m.DescribeNetworkAclsResponse, true)
+	return m.err
+}
+
+func networkACLEntry(ruleNumber int64, egress bool) *ec2.NetworkAclEntry {
+	return &ec2.NetworkAclEntry{RuleNumber: aws.Int64(ruleNumber), Egress: aws.Bool(egress)}
+}
+
+func testNetworkACLs() *ec2.DescribeNetworkAclsOutput {
+	return &ec2.DescribeNetworkAclsOutput{
+		NetworkAcls: []*ec2.NetworkAcl{
+			{
+				NetworkAclId: aws.String("acl-1"),
+				VpcId:        aws.String("vpc-1"),
+				Entries: []*ec2.NetworkAclEntry{
+					networkACLEntry(100, false),
+					networkACLEntry(110, false),
+					networkACLEntry(32767, false),
+					networkACLEntry(100, true),
+					networkACLEntry(32767, true),
+				},
+				Tags: []*ec2.Tag{{Key: aws.String("team"), Value: aws.String("networking")}},
+			},
+			{
+				NetworkAclId: aws.String("acl-2"),
+				VpcId:        aws.String("vpc-1"),
+				Entries: []*ec2.NetworkAclEntry{
+					networkACLEntry(32767, false),
+					networkACLEntry(32767, true),
+				},
+			},
+		},
+	}
+}
+
+func ipv6NetworkACLEntry(ruleNumber int64, egress bool) *ec2.NetworkAclEntry {
+	entry := networkACLEntry(ruleNumber, egress)
+	entry.Ipv6CidrBlock = aws.String("::/0")
+	return entry
+}
+
+func TestNetworkACLUsageChecksWithError(t *testing.T) {
+	mockClient := &mockEC2Client{
+		err: errors.New("some err"),
+	}
+
+	testCases := []struct {
+		name  string
+		check UsageCheck
+	}{
+		{name: "NetworkACLsPerVPC", check: &NetworkACLsPerVPCUsageCheck{client: mockClient, vpcs: newVPCsCache(mockClient, testAccountID())}},
+		{name: "RulesPerNetworkACL", check: &RulesPerNetworkACLUsageCheck{mockClient}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			usage, err := tc.check.Usage()
+
+			assert.Error(t, err)
+			assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+			assert.Nil(t, usage)
+		})
+	}
+}
+
+func TestNetworkACLsPerVPCUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeVpcsResponse: &ec2.DescribeVpcsOutput{
+			Vpcs: []*ec2.Vpc{{VpcId: aws.String("vpc-1"), OwnerId: aws.String("123456789012")}},
+		},
+		DescribeNetworkAclsResponse: testNetworkACLs(),
+	}
+
+	check := NetworkACLsPerVPCUsageCheck{client: mockClient, vpcs: newVPCsCache(mockClient, testAccountID())}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         networkACLsPerVPCName,
+			ResourceName: aws.String("vpc-1"),
+			Description:  networkACLsPerVPCDesc,
+			Usage:        2,
+			Labels:       map[string]string{"vpc_id": "vpc-1"},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
+
+func TestRulesPerNetworkACLUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeNetworkAclsResponse: testNetworkACLs(),
+	}
+
+	check := RulesPerNetworkACLUsageCheck{mockClient}
+	usage, err := check.Usage()
+
+	tags := map[string]string{"team": "networking"}
+	labels := map[string]string{"vpc_id": "vpc-1"}
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         inboundRulesPerNetworkACLName,
+			ResourceName: aws.String("acl-1"),
+			Description:  inboundRulesPerNetworkACLDesc,
+			Usage:        2,
+			Tags:         tags,
+			Labels:       labels,
+		},
+		{
+			Name:         outboundRulesPerNetworkACLName,
+			ResourceName: aws.String("acl-1"),
+			Description:  outboundRulesPerNetworkACLDesc,
+			Usage:        1,
+			Tags:         tags,
+			Labels:       labels,
+		},
+		{
+			Name:         inboundRulesPerNetworkACLName,
+			ResourceName: aws.String("acl-2"),
+			Description:  inboundRulesPerNetworkACLDesc,
+			Usage:        0,
+			Labels:       labels,
+		},
+		{
+			Name:         outboundRulesPerNetworkACLName,
+			ResourceName: aws.String("acl-2"),
+			Description:  outboundRulesPerNetworkACLDesc,
+			Usage:        0,
+			Labels:       labels,
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
+
+func TestRulesPerNetworkACLUsageWithIPv6Rules(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeNetworkAclsResponse: &ec2.DescribeNetworkAclsOutput{
+			NetworkAcls: []*ec2.NetworkAcl{
+				{
+					NetworkAclId: aws.String("acl-dualstack"),
+					VpcId:        aws.String("vpc-1"),
+					Entries: []*ec2.NetworkAclEntry{
+						networkACLEntry(100, false),
+						networkACLEntry(110, false),
+						ipv6NetworkACLEntry(101, false),
+						ipv6NetworkACLEntry(111, false),
+						ipv6NetworkACLEntry(121, false),
+						networkACLEntry(32767, false),
+						ipv6NetworkACLEntry(32768, false),
+						networkACLEntry(100, true),
+						networkACLEntry(110, true),
+						ipv6NetworkACLEntry(101, true),
+						networkACLEntry(32767, true),
+						ipv6NetworkACLEntry(32768, true),
+					},
+				},
+			},
+		},
+	}
+
+	check := RulesPerNetworkACLUsageCheck{mockClient}
+	usage, err := check.Usage()
+
+	labels := map[string]string{"vpc_id": "vpc-1"}
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         inboundRulesPerNetworkACLName,
+			ResourceName: aws.String("acl-dualstack"),
+			Description:  inboundRulesPerNetworkACLDesc,
+			Usage:        3,
+			Labels:       labels,
+		},
+		{
+			Name:         outboundRulesPerNetworkACLName,
+			ResourceName: aws.String("acl-dualstack"),
+			Description:  outboundRulesPerNetworkACLDesc,
+			Usage:        2,
+			Labels:       labels,
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
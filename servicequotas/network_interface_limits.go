@@ -0,0 +1,144 @@
+package servicequotas
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const (
+	networkInterfacesPerRegionName = "network_interfaces_per_region"
+	networkInterfacesPerRegionDesc = "network interfaces per region"
+
+	networkInterfacesPerRegionBreakdownName = "network_interfaces_per_region_breakdown"
+	networkInterfacesPerRegionBreakdownDesc = "network interfaces per region by interface type and requester"
+
+	interfaceTypeLabel = "interface_type"
+	requesterLabel     = "requester"
+
+	lambdaRequester = "lambda"
+	eksRequester    = "eks"
+	elbRequester    = "elb"
+	natRequester    = "nat"
+	otherRequester  = "other"
+)
+
+// NetworkInterfacesPerRegionUsageCheck implements the UsageCheck
+// interface for network interfaces per region
+type NetworkInterfacesPerRegionUsageCheck struct {
+	networkInterfaces *networkInterfacesCache
+	// breakdown additionally reports the network interfaces per
+	// interface type and requester
+	breakdown bool
+}
+
+// networkInterfacesBreakdownKey identifies a series of the network
+// interfaces breakdown
+type networkInterfacesBreakdownKey struct {
+	interfaceType string
+	requester     string
+}
+
+// Usage returns usage for network interfaces per region as the number
+// of all network interfaces in the region, and with the breakdown
+// enabled their number per interface type and requester, or an error
+func (c *NetworkInterfacesPerRegionUsageCheck) Usage() ([]QuotaUsage, error) {
+	networkInterfaces, err := c.networkInterfaces.NetworkInterfaces()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	usage := []QuotaUsage{
+		{
+			Name:        networkInterfacesPerRegionName,
+			Description: networkInterfacesPerRegionDesc,
+			Usage:       float64(len(networkInterfaces)),
+		},
+	}
+	if !c.breakdown {
+		return usage, nil
+	}
+
+	breakdown := map[networkInterfacesBreakdownKey]int{}
+	for _, eni := range networkInterfaces {
+		key := networkInterfacesBreakdownKey{
+			interfaceType: aws.StringValue(eni.InterfaceType),
+			requester:     networkInterfaceRequester(eni),
+		}
+		breakdown[key]++
+	}
+
+	breakdownUsage := make([]QuotaUsage, 0, len(breakdown))
+	for key, count := range breakdown {
+		resourceName := fmt.Sprintf("%s/%s", key.interfaceType, key.requester)
+		breakdownUsage = append(breakdownUsage, QuotaUsage{
+			Name:         networkInterfacesPerRegionBreakdownName,
+			ResourceName: &resourceName,
+			Description:  networkInterfacesPerRegionBreakdownDesc,
+			Usage:        float64(count),
+			Labels: map[string]string{
+				interfaceTypeLabel: key.interfaceType,
+				requesterLabel:     key.requester,
+			},
+		})
+	}
+
+	sort.Slice(breakdownUsage, func(i, j int) bool {
+		return *breakdownUsage[i].ResourceName < *breakdownUsage[j].ResourceName
+	})
+	return append(usage, breakdownUsage...), nil
+}
+
+// Definitions returns the network interfaces per region quota and,
+// with the breakdown enabled, its breakdown by interface type and
+// requester
+func (c *NetworkInterfacesPerRegionUsageCheck) Definitions() []QuotaDefinition {
+	definitions := []QuotaDefinition{
+		{Name: networkInterfacesPerRegionName, Description: networkInterfacesPerRegionDesc},
+	}
+	if c.breakdown {
+		definitions = append(definitions, QuotaDefinition{
+			Name:        networkInterfacesPerRegionBreakdownName,
+			Description: networkInterfacesPerRegionBreakdownDesc,
+			Breakdown:   true,
+		})
+	}
+	return definitions
+}
+
+// networkInterfaceRequester returns the service that created `eni`,
+// recognised from its interface type, requester and description, or
+// otherRequester
+func networkInterfaceRequester(eni *ec2.NetworkInterface) string {
+	interfaceType := aws.StringValue(eni.InterfaceType)
+	requesterID := aws.StringValue(eni.RequesterId)
+	description := aws.StringValue(eni.Description)
+
+	switch {
+	case interfaceType == ec2.NetworkInterfaceTypeNatGateway || strings.HasPrefix(description, "Interface for NAT Gateway"):
+		return natRequester
+	case interfaceType == ec2.NetworkInterfaceTypeLambda || strings.HasPrefix(description, "AWS Lambda VPC ENI"):
+		return lambdaRequester
+	case interfaceType == ec2.NetworkInterfaceTypeNetworkLoadBalancer || requesterID == "amazon-elb" || strings.HasPrefix(description, "ELB "):
+		return elbRequester
+	case strings.HasPrefix(description, "Amazon EKS") || strings.HasPrefix(description, "aws-K8S-") || hasEKSTag(eni.TagSet):
+		return eksRequester
+	default:
+		return otherRequester
+	}
+}
+
+// hasEKSTag returns whether `tags` include a tag the Amazon VPC CNI
+// plugin of EKS tags the network interfaces it creates with
+func hasEKSTag(tags []*ec2.Tag) bool {
+	for _, tag := range tags {
+		key := aws.StringValue(tag.Key)
+		if key == "cluster.k8s.amazonaws.com/name" || key == "eks:eni:owner" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func testNetworkInterfaces() *ec2.DescribeNetworkInterfacesOutput {
+	return &ec2.DescribeNetworkInterfacesOutput{
+		NetworkInterfaces: []*ec2.NetworkInterface{
+			{
+				NetworkInterfaceId: aws.String("eni-lambda-1"),
+				InterfaceType:      aws.String("lambda"),
+				Description:        aws.String("AWS Lambda VPC ENI-my-function"),
+			},
+			{
+				NetworkInterfaceId: aws.String("eni-lambda-2"),
+				InterfaceType:      aws.String("lambda"),
+				Description:        aws.String("AWS Lambda VPC ENI-my-other-function"),
+			},
+			{
+				NetworkInterfaceId: aws.String("eni-eks-control-plane"),
+				InterfaceType:      aws.String("interface"),
+				Description:        aws.String("Amazon EKS my-cluster"),
+			},
+			{
+				NetworkInterfaceId: aws.String("eni-eks-pods"),
+				InterfaceType:      aws.String("interface"),
+				Description:        aws.String("aws-K8S-i-0123456789abcdef0"),
+				TagSet:             []*ec2.Tag{{Key: aws.String("cluster.k8s.amazonaws.com/name"), Value: aws.String("my-cluster")}},
+			},
+			{
+				NetworkInterfaceId: aws.String("eni-alb"),
+				InterfaceType:      aws.String("interface"),
+				RequesterId:        aws.String("amazon-elb"),
+				Description:        aws.String("ELB app/my-alb/0123456789abcdef"),
+			},
+			{
+				NetworkInterfaceId: aws.String("eni-nat"),
+				InterfaceType:      aws.String("natGateway"),
+				Description:        aws.String("Interface for NAT Gateway nat-0123456789abcdef0"),
+			},
+			{
+				NetworkInterfaceId: aws.String("eni-instance"),
+				InterfaceType:      aws.String("interface"),
+			},
+		},
+	}
+}
+
+func TestNetworkInterfacesPerRegionUsageWithError(t *testing.T) {
+	mockClient := &mockEC2Client{err: errors.New("some err")}
+
+	check := NetworkInterfacesPerRegionUsageCheck{networkInterfaces: newNetworkInterfacesCache(mockClient)}
+	usage, err := check.Usage()
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+	assert.Nil(t, usage)
+}
+
+func TestNetworkInterfacesPerRegionUsage(t *testing.T) {
+	total := QuotaUsage{
+		Name:        networkInterfacesPerRegionName,
+		Description: networkInterfacesPerRegionDesc,
+		Usage:       7,
+	}
+	breakdown := func(interfaceType, requester string, usage float64) QuotaUsage {
+		return QuotaUsage{
+			Name:         networkInterfacesPerRegionBreakdownName,
+			ResourceName: aws.String(interfaceType + "/" + requester),
+			Description:  networkInterfacesPerRegionBreakdownDesc,
+			Usage:        usage,
+			Labels:       map[string]string{"interface_type": interfaceType, "requester": requester},
+		}
+	}
+
+	testCases := []struct {
+		name                string
+		breakdown           bool
+		expectedUsage       []QuotaUsage
+		expectedDefinitions []QuotaDefinition
+	}{
+		{
+			name:          "WithoutBreakdown",
+			breakdown:     false,
+			expectedUsage: []QuotaUsage{total},
+			expectedDefinitions: []QuotaDefinition{
+				{Name: networkInterfacesPerRegionName, Description: networkInterfacesPerRegionDesc},
+			},
+		},
+		{
+			name:      "WithBreakdown",
+			breakdown: true,
+			expectedUsage: []QuotaUsage{
+				total,
+				breakdown("interface", "eks", 2),
+				breakdown("interface", "elb", 1),
+				breakdown("interface", "other", 1),
+				breakdown("lambda", "lambda", 2),
+				breakdown("natGateway", "nat", 1),
+			},
+			expectedDefinitions: []QuotaDefinition{
+				{Name: networkInterfacesPerRegionName, Description: networkInterfacesPerRegionDesc},
+				{Name: networkInterfacesPerRegionBreakdownName, Description: networkInterfacesPerRegionBreakdownDesc, Breakdown: true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := &mockEC2Client{
+				DescribeNetworkInterfacesResponse: testNetworkInterfaces(),
+			}
+
+			check := NetworkInterfacesPerRegionUsageCheck{networkInterfaces: newNetworkInterfacesCache(mockClient), breakdown: tc.breakdown}
+			usage, err := check.Usage()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedUsage, usage)
+			assert.Equal(t, tc.expectedDefinitions, check.Definitions())
+		})
+	}
+}
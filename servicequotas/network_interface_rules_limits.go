@@ -0,0 +1,107 @@
+package servicequotas
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const (
+	inboundRulesPerENIName = "inbound_rules_per_network_interface"
+	inboundRulesPerENIDesc = "inbound security group rules per network interface"
+
+	outboundRulesPerENIName = "outbound_rules_per_network_interface"
+	outboundRulesPerENIDesc = "outbound security group rules per network interface"
+
+	// rulesPerENI is the fixed limit of the security groups per
+	// network interface quota multiplied by the rules per security
+	// group quota, which caps the rules of all security groups of a
+	// network interface
+	// https://docs.aws.amazon.com/vpc/latest/userguide/amazon-vpc-limits.html#vpc-limits-security-groups
+	rulesPerENI = 1000
+)
+
+// RulesPerENIUsageCheck implements the UsageCheck interface for the
+// security group rules of all security groups of a network interface
+type RulesPerENIUsageCheck struct {
+	securityGroups    *securityGroupsCache
+	networkInterfaces *networkInterfacesCache
+}
+
+// securityGroupRules are the inbound and outbound rules of a security
+// group as counted by AWS
+type securityGroupRules struct {
+	inbound  int64
+	outbound int64
+}
+
+// Usage returns the usage for each network interface ID with the usage
+// value being the sum of the inbound and outbound rules of all its
+// security groups as counted by AWS or an error
+func (c *RulesPerENIUsageCheck) Usage() ([]QuotaUsage, error) {
+	weights, err := c.securityGroups.PrefixListWeights()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	securityGroups, err := c.securityGroups.SecurityGroups()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	rules := map[string]securityGroupRules{}
+	for _, group := range securityGroups {
+		rules[aws.StringValue(group.GroupId)] = securityGroupRules{
+			inbound:  securityGroupRuleCount(group.IpPermissions, weights),
+			outbound: securityGroupRuleCount(group.IpPermissionsEgress, weights),
+		}
+	}
+
+	networkInterfaces, err := c.networkInterfaces.NetworkInterfaces()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	quotaUsages := []QuotaUsage{}
+	for _, eni := range networkInterfaces {
+		var inboundRules, outboundRules int64
+		for _, group := range eni.Groups {
+			groupRules := rules[aws.StringValue(group.GroupId)]
+			inboundRules += groupRules.inbound
+			outboundRules += groupRules.outbound
+		}
+
+		tags := ec2TagsToQuotaUsageTags(eni.TagSet)
+
+		inboundUsage := QuotaUsage{
+			Name:         inboundRulesPerENIName,
+			ResourceName: eni.NetworkInterfaceId,
+			Description:  inboundRulesPerENIDesc,
+			Usage:        float64(inboundRules),
+			Quota:        rulesPerENI,
+			Tags:         tags,
+		}
+
+		outboundUsage := QuotaUsage{
+			Name:         outboundRulesPerENIName,
+			ResourceName: eni.NetworkInterfaceId,
+			Description:  outboundRulesPerENIDesc,
+			Usage:        float64(outboundRules),
+			Quota:        rulesPerENI,
+			Tags:         tags,
+		}
+
+		quotaUsages = append(quotaUsages, []QuotaUsage{inboundUsage, outboundUsage}...)
+	}
+
+	return quotaUsages, nil
+}
+
+// Definitions returns the inbound and outbound rules per network
+// interface quotas
+func (c *RulesPerENIUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{
+		{Name: inboundRulesPerENIName, Description: inboundRulesPerENIDesc},
+		{Name: outboundRulesPerENIName, Description: outboundRulesPerENIDesc},
+	}
+}
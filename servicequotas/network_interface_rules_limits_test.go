@@ -0,0 +1,110 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRulesPerENIUsageWithError(t *testing.T) {
+	mockClient := &mockEC2Client{err: errors.New("some err")}
+
+	check := RulesPerENIUsageCheck{securityGroups: newSecurityGroupsCache(mockClient), networkInterfaces: newNetworkInterfacesCache(mockClient)}
+	usage, err := check.Usage()
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+	assert.Nil(t, usage)
+}
+
+func TestRulesPerENIUsage(t *testing.T) {
+	cidrPermission := func(cidrs ...string) *ec2.IpPermission {
+		permission := &ec2.IpPermission{}
+		for _, cidr := range cidrs {
+			permission.IpRanges = append(permission.IpRanges, &ec2.IpRange{CidrIp: aws.String(cidr)})
+		}
+		return permission
+	}
+
+	mockClient := &mockEC2Client{
+		DescribeManagedPrefixListsResponse: &ec2.DescribeManagedPrefixListsOutput{
+			PrefixLists: []*ec2.ManagedPrefixList{
+				{PrefixListId: aws.String("pl-office"), MaxEntries: aws.Int64(50)},
+			},
+		},
+		DescribeSecurityGroupsResponse: &ec2.DescribeSecurityGroupsOutput{
+			SecurityGroups: []*ec2.SecurityGroup{
+				{
+					GroupId:             aws.String("sg-web"),
+					IpPermissions:       []*ec2.IpPermission{cidrPermission("0.0.0.0/0", "10.0.0.0/8")},
+					IpPermissionsEgress: []*ec2.IpPermission{cidrPermission("0.0.0.0/0")},
+				},
+				{
+					GroupId: aws.String("sg-office"),
+					IpPermissions: []*ec2.IpPermission{
+						{PrefixListIds: []*ec2.PrefixListId{{PrefixListId: aws.String("pl-office")}}},
+					},
+				},
+			},
+		},
+		DescribeNetworkInterfacesResponse: &ec2.DescribeNetworkInterfacesOutput{
+			NetworkInterfaces: []*ec2.NetworkInterface{
+				{
+					NetworkInterfaceId: aws.String("eni-1"),
+					Groups: []*ec2.GroupIdentifier{
+						{GroupId: aws.String("sg-web")},
+						{GroupId: aws.String("sg-office")},
+					},
+					TagSet: []*ec2.Tag{{Key: aws.String("team"), Value: aws.String("web")}},
+				},
+				{
+					NetworkInterfaceId: aws.String("eni-2"),
+					Groups:             []*ec2.GroupIdentifier{{GroupId: aws.String("sg-unknown")}},
+				},
+			},
+		},
+	}
+
+	check := RulesPerENIUsageCheck{securityGroups: newSecurityGroupsCache(mockClient), networkInterfaces: newNetworkInterfacesCache(mockClient)}
+	usage, err := check.Usage()
+
+	tags := map[string]string{"team": "web"}
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         inboundRulesPerENIName,
+			ResourceName: aws.String("eni-1"),
+			Description:  inboundRulesPerENIDesc,
+			Usage:        52,
+			Quota:        1000,
+			Tags:         tags,
+		},
+		{
+			Name:         outboundRulesPerENIName,
+			ResourceName: aws.String("eni-1"),
+			Description:  outboundRulesPerENIDesc,
+			Usage:        1,
+			Quota:        1000,
+			Tags:         tags,
+		},
+		{
+			Name:         inboundRulesPerENIName,
+			ResourceName: aws.String("eni-2"),
+			Description:  inboundRulesPerENIDesc,
+			Usage:        0,
+			Quota:        1000,
+		},
+		{
+			Name:         outboundRulesPerENIName,
+			ResourceName: aws.String("eni-2"),
+			Description:  outboundRulesPerENIDesc,
+			Usage:        0,
+			Quota:        1000,
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
@@ -0,0 +1,53 @@
+package servicequotas
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// networkInterfacesCache caches the network interfaces so usage
+// checks based on network interfaces don't describe them separately
+type networkInterfacesCache struct {
+	client            ec2iface.EC2API
+	ttl               time.Duration
+	lock              sync.Mutex
+	networkInterfaces []*ec2.NetworkInterface
+	lastRefresh       time.Time
+}
+
+func newNetworkInterfacesCache(client ec2iface.EC2API) *networkInterfacesCache {
+	return &networkInterfacesCache{client: client, ttl: instancesCacheTTL}
+}
+
+// NetworkInterfaces returns the network interfaces, describing them
+// if the cached network interfaces are older than the cache's TTL, or
+// an error
+func (c *networkInterfacesCache) NetworkInterfaces() ([]*ec2.NetworkInterface, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.networkInterfaces != nil && time.Since(c.lastRefresh) < c.ttl {
+		return c.networkInterfaces, nil
+	}
+
+	networkInterfaces := []*ec2.NetworkInterface{}
+	params := &ec2.DescribeNetworkInterfacesInput{}
+	err := c.client.DescribeNetworkInterfacesPages(params,
+		func(page *ec2.DescribeNetworkInterfacesOutput, lastPage bool) bool {
+			if page != nil {
+				networkInterfaces = append(networkInterfaces, page.NetworkInterfaces...)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	c.networkInterfaces = networkInterfaces
+	c.lastRefresh = time.Now()
+	return networkInterfaces, nil
+}
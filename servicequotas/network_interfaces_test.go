@@ -0,0 +1,46 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkInterfacesCacheWithError(t *testing.T) {
+	mockClient := &mockEC2Client{err: errors.New("some err")}
+
+	networkInterfaces, err := newNetworkInterfacesCache(mockClient).NetworkInterfaces()
+
+	assert.Error(t, err)
+	assert.Nil(t, networkInterfaces)
+}
+
+func TestNetworkInterfacesCache(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeNetworkInterfacesResponse: &ec2.DescribeNetworkInterfacesOutput{
+			NetworkInterfaces: []*ec2.NetworkInterface{
+				{NetworkInterfaceId: aws.String("eni-1")},
+				{NetworkInterfaceId: aws.String("eni-2")},
+			},
+		},
+	}
+	cache := newNetworkInterfacesCache(mockClient)
+
+	for i := 0; i < 3; i++ {
+		networkInterfaces, err := cache.NetworkInterfaces()
+
+		assert.NoError(t, err)
+		assert.Len(t, networkInterfaces, 2)
+	}
+	assert.Equal(t, 1, mockClient.DescribeNetworkInterfacesCalls)
+
+	cache.lastRefresh = time.Now().Add(-instancesCacheTTL)
+	_, err := cache.NetworkInterfaces()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, mockClient.DescribeNetworkInterfacesCalls)
+}
@@ -0,0 +1,151 @@
+package servicequotas
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+const (
+	entriesPerPrefixListName = "entries_per_prefix_list"
+	entriesPerPrefixListDesc = "entries per managed prefix list"
+
+	prefixListsPerRegionName = "prefix_lists_per_region"
+	prefixListsPerRegionDesc = "managed prefix lists per region"
+
+	// prefixListsPerRegionQuotaName is the name the prefix lists per
+	// region quota is listed under in Service Quotas
+	prefixListsPerRegionQuotaName = "Prefix lists per Region"
+	// defaultPrefixListsPerRegion is the default quota of customer
+	// managed prefix lists per region, used when the quota is not
+	// listed
+	// https://docs.aws.amazon.com/vpc/latest/userguide/amazon-vpc-limits.html#vpc-limits-prefix-lists
+	defaultPrefixListsPerRegion = 100
+
+	deletedPrefixListState = ec2.PrefixListStateDeleteComplete
+
+	prefixListNameLabel = "prefix_list_name"
+)
+
+// PrefixListEntriesUsageCheck implements the UsageCheck interface for
+// entries per customer managed prefix list
+type PrefixListEntriesUsageCheck struct {
+	client    ec2iface.EC2API
+	accountID *accountIDCache
+}
+
+// Usage returns usage for each customer managed prefix list ID with
+// the usage value being the number of entries of its current version
+// and the quota its max entries or an error
+func (c *PrefixListEntriesUsageCheck) Usage() ([]QuotaUsage, error) {
+	prefixLists, err := describeCustomerManagedPrefixLists(c.client, c.accountID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	quotaUsages := []QuotaUsage{}
+	for _, prefixList := range prefixLists {
+		if prefixList.PrefixListId == nil {
+			continue
+		}
+
+		numEntries := 0
+		params := &ec2.GetManagedPrefixListEntriesInput{PrefixListId: prefixList.PrefixListId}
+		err := c.client.GetManagedPrefixListEntriesPages(params,
+			func(page *ec2.GetManagedPrefixListEntriesOutput, lastPage bool) bool {
+				if page != nil {
+					numEntries += len(page.Entries)
+				}
+				return !lastPage
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+		}
+
+		quotaUsages = append(quotaUsages, QuotaUsage{
+			Name:         entriesPerPrefixListName,
+			ResourceName: prefixList.PrefixListId,
+			Description:  entriesPerPrefixListDesc,
+			Usage:        float64(numEntries),
+			Quota:        float64(aws.Int64Value(prefixList.MaxEntries)),
+			Tags:         ec2TagsToQuotaUsageTags(prefixList.Tags),
+			Labels:       map[string]string{prefixListNameLabel: aws.StringValue(prefixList.PrefixListName)},
+		})
+	}
+
+	return quotaUsages, nil
+}
+
+// Definitions returns the entries per prefix list quota
+func (c *PrefixListEntriesUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: entriesPerPrefixListName, Description: entriesPerPrefixListDesc}}
+}
+
+// PrefixListsPerRegionUsageCheck implements the UsageCheck interface
+// for customer managed prefix lists per region
+type PrefixListsPerRegionUsageCheck struct {
+	listedQuotas
+	client    ec2iface.EC2API
+	accountID *accountIDCache
+}
+
+func (c *PrefixListsPerRegionUsageCheck) serviceCode() string {
+	return "vpc"
+}
+
+// Usage returns usage for prefix lists per region as the number of
+// customer managed prefix lists in the region, with the listed quota
+// or its default, or an error
+func (c *PrefixListsPerRegionUsageCheck) Usage() ([]QuotaUsage, error) {
+	prefixLists, err := describeCustomerManagedPrefixLists(c.client, c.accountID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	usage := []QuotaUsage{
+		{
+			Name:        prefixListsPerRegionName,
+			Description: prefixListsPerRegionDesc,
+			Usage:       float64(len(prefixLists)),
+			Quota:       c.quota(prefixListsPerRegionQuotaName, defaultPrefixListsPerRegion),
+		},
+	}
+	return usage, nil
+}
+
+// Definitions returns the prefix lists per region quota
+func (c *PrefixListsPerRegionUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: prefixListsPerRegionName, Description: prefixListsPerRegionDesc}}
+}
+
+// describeCustomerManagedPrefixLists returns the prefix lists owned
+// by the account that have not been deleted. AWS managed prefix lists
+// and prefix lists shared from other accounts do not count against
+// the quotas
+func describeCustomerManagedPrefixLists(client ec2iface.EC2API, accountIDs *accountIDCache) ([]*ec2.ManagedPrefixList, error) {
+	accountID, err := accountIDs.AccountID()
+	if err != nil {
+		return nil, err
+	}
+
+	prefixLists := []*ec2.ManagedPrefixList{}
+	err = client.DescribeManagedPrefixListsPages(&ec2.DescribeManagedPrefixListsInput{},
+		func(page *ec2.DescribeManagedPrefixListsOutput, lastPage bool) bool {
+			if page != nil {
+				for _, prefixList := range page.PrefixLists {
+					if aws.StringValue(prefixList.OwnerId) == accountID && aws.StringValue(prefixList.State) != deletedPrefixListState {
+						prefixLists = append(prefixLists, prefixList)
+					}
+				}
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return prefixLists, nil
+}
@@ -0,0 +1,152 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	awsservicequotas "github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/stretchr/testify/assert"
+)
+
+func (m *mockEC2Client) GetManagedPrefixListEntriesPages(input *ec2.GetManagedPrefixListEntriesInput, fn func(*ec2.GetManagedPrefixListEntriesOutput, bool) bool) error {
+	fn(m.GetManagedPrefixListEntriesResponses[*input.PrefixListId], true)
+	return m.err
+}
+
+func testManagedPrefixLists() *ec2.DescribeManagedPrefixListsOutput {
+	return &ec2.DescribeManagedPrefixListsOutput{
+		PrefixLists: []*ec2.ManagedPrefixList{
+			{
+				PrefixListId:   aws.String("pl-6da54004"),
+				PrefixListName: aws.String("com.amazonaws.eu-west-1.s3"),
+				OwnerId:        aws.String("AWS"),
+				State:          aws.String("create-complete"),
+			},
+			{
+				PrefixListId:   aws.String("pl-office"),
+				PrefixListName: aws.String("office-ranges"),
+				OwnerId:        aws.String("123456789012"),
+				State:          aws.String("modify-complete"),
+				MaxEntries:     aws.Int64(20),
+				Tags:           []*ec2.Tag{{Key: aws.String("team"), Value: aws.String("networking")}},
+			},
+			{
+				PrefixListId:   aws.String("pl-deleted"),
+				PrefixListName: aws.String("old-ranges"),
+				OwnerId:        aws.String("123456789012"),
+				State:          aws.String("delete-complete"),
+				MaxEntries:     aws.Int64(10),
+			},
+			{
+				PrefixListId:   aws.String("pl-shared"),
+				PrefixListName: aws.String("shared-ranges"),
+				OwnerId:        aws.String("210987654321"),
+				State:          aws.String("create-complete"),
+				MaxEntries:     aws.Int64(50),
+			},
+		},
+	}
+}
+
+func TestPrefixListUsageChecksWithError(t *testing.T) {
+	mockClient := &mockEC2Client{
+		err: errors.New("some err"),
+	}
+
+	testCases := []struct {
+		name  string
+		check UsageCheck
+	}{
+		{name: "PrefixListEntries", check: &PrefixListEntriesUsageCheck{client: mockClient, accountID: testAccountID()}},
+		{name: "PrefixListsPerRegion", check: &PrefixListsPerRegionUsageCheck{client: mockClient, accountID: testAccountID()}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			usage, err := tc.check.Usage()
+
+			assert.Error(t, err)
+			assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+			assert.Nil(t, usage)
+		})
+	}
+}
+
+func TestPrefixListEntriesUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeManagedPrefixListsResponse: testManagedPrefixLists(),
+		GetManagedPrefixListEntriesResponses: map[string]*ec2.GetManagedPrefixListEntriesOutput{
+			"pl-office": {
+				Entries: []*ec2.PrefixListEntry{
+					{Cidr: aws.String("198.51.100.0/24")},
+					{Cidr: aws.String("203.0.113.0/24")},
+				},
+			},
+		},
+	}
+
+	check := PrefixListEntriesUsageCheck{client: mockClient, accountID: testAccountID()}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         entriesPerPrefixListName,
+			ResourceName: aws.String("pl-office"),
+			Description:  entriesPerPrefixListDesc,
+			Usage:        2,
+			Quota:        20,
+			Tags:         map[string]string{"team": "networking"},
+			Labels:       map[string]string{"prefix_list_name": "office-ranges"},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
+
+func TestPrefixListsPerRegionUsage(t *testing.T) {
+	testCases := []struct {
+		name          string
+		quotas        []*awsservicequotas.ServiceQuota
+		expectedQuota float64
+	}{
+		{
+			name:          "WithDefaultQuota",
+			expectedQuota: 100,
+		},
+		{
+			name: "WithListedQuota",
+			quotas: []*awsservicequotas.ServiceQuota{
+				{QuotaName: aws.String("Prefix lists per Region"), Value: aws.Float64(250)},
+				{QuotaName: aws.String("VPCs per Region"), Value: aws.Float64(5)},
+			},
+			expectedQuota: 250,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := &mockEC2Client{
+				DescribeManagedPrefixListsResponse: testManagedPrefixLists(),
+			}
+
+			check := PrefixListsPerRegionUsageCheck{client: mockClient, accountID: testAccountID()}
+			check.setQuotas(tc.quotas)
+			usage, err := check.Usage()
+
+			expectedUsage := []QuotaUsage{
+				{
+					Name:        prefixListsPerRegionName,
+					Description: prefixListsPerRegionDesc,
+					Usage:       1,
+					Quota:       tc.expectedQuota,
+				},
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, expectedUsage, usage)
+		})
+	}
+}
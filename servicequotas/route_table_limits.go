@@ -0,0 +1,178 @@
+package servicequotas
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const (
+	routeTablesPerVPCName = "route_tables_per_vpc"
+	routeTablesPerVPCDesc = "route tables per VPC"
+
+	routesPerRouteTableName = "routes_per_route_table"
+	routesPerRouteTableDesc = "non-propagated routes per route table"
+
+	propagatedRoutesPerRouteTableName = "propagated_routes_per_route_table"
+	propagatedRoutesPerRouteTableDesc = "propagated routes per route table"
+
+	// propagatedRoutesPerRouteTable is the fixed limit of routes
+	// propagated from a virtual private gateway to a route table,
+	// which is not reported by Service Quotas
+	// https://docs.aws.amazon.com/vpc/latest/userguide/amazon-vpc-limits.html#vpc-limits-route-tables
+	propagatedRoutesPerRouteTable = 100
+
+	propagatedRouteOrigin = "EnableVgwRoutePropagation"
+	localRouteGatewayID   = "local"
+)
+
+// RouteTablesPerVPCUsageCheck implements the UsageCheck interface for
+// route tables per VPC
+type RouteTablesPerVPCUsageCheck struct {
+	vpcs *vpcsCache
+}
+
+// Usage returns usage for each VPC ID with the usage value being the
+// number of route tables in the VPC, including its main route table,
+// or an error
+func (c *RouteTablesPerVPCUsageCheck) Usage() ([]QuotaUsage, error) {
+	vpcs, err := c.vpcs.OwnedVPCs()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	routeTables, err := c.vpcs.RouteTables()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	routeTablesPerVPC := map[string]int{}
+	for _, routeTable := range routeTables {
+		routeTablesPerVPC[aws.StringValue(routeTable.VpcId)]++
+	}
+
+	return vpcCountUsages(routeTablesPerVPCName, routeTablesPerVPCDesc, vpcs, routeTablesPerVPC), nil
+}
+
+// Definitions returns the route tables per VPC quota
+func (c *RouteTablesPerVPCUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: routeTablesPerVPCName, Description: routeTablesPerVPCDesc}}
+}
+
+// RoutesPerRouteTableUsageCheck implements the UsageCheck interface
+// for non-propagated routes per route table
+type RoutesPerRouteTableUsageCheck struct {
+	vpcs           *vpcsCache
+	securityGroups *securityGroupsCache
+}
+
+// Usage returns usage for each route table ID with the usage value
+// being the number of its non-propagated routes as counted by AWS or
+// an error
+func (c *RoutesPerRouteTableUsageCheck) Usage() ([]QuotaUsage, error) {
+	weights, err := c.securityGroups.PrefixListWeights()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	routeTables, err := c.vpcs.RouteTables()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	quotaUsages := []QuotaUsage{}
+	for _, routeTable := range routeTables {
+		if routeTable.RouteTableId == nil {
+			continue
+		}
+
+		quotaUsages = append(quotaUsages, QuotaUsage{
+			Name:         routesPerRouteTableName,
+			ResourceName: routeTable.RouteTableId,
+			Description:  routesPerRouteTableDesc,
+			Usage:        float64(staticRouteCount(routeTable.Routes, weights)),
+			Tags:         ec2TagsToQuotaUsageTags(routeTable.Tags),
+			Labels:       map[string]string{vpcIDLabel: aws.StringValue(routeTable.VpcId)},
+		})
+	}
+
+	return quotaUsages, nil
+}
+
+// Definitions returns the non-propagated routes per route table quota
+func (c *RoutesPerRouteTableUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: routesPerRouteTableName, Description: routesPerRouteTableDesc}}
+}
+
+// PropagatedRoutesPerRouteTableUsageCheck implements the UsageCheck
+// interface for routes propagated from a virtual private gateway per
+// route table
+type PropagatedRoutesPerRouteTableUsageCheck struct {
+	vpcs *vpcsCache
+}
+
+// Usage returns usage for each route table ID with propagated routes
+// with the usage value being the number of its propagated routes or
+// an error
+func (c *PropagatedRoutesPerRouteTableUsageCheck) Usage() ([]QuotaUsage, error) {
+	routeTables, err := c.vpcs.RouteTables()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	quotaUsages := []QuotaUsage{}
+	for _, routeTable := range routeTables {
+		if routeTable.RouteTableId == nil || len(routeTable.PropagatingVgws) == 0 {
+			continue
+		}
+
+		propagatedRoutes := 0
+		for _, route := range routeTable.Routes {
+			if aws.StringValue(route.Origin) == propagatedRouteOrigin {
+				propagatedRoutes++
+			}
+		}
+
+		quotaUsages = append(quotaUsages, QuotaUsage{
+			Name:         propagatedRoutesPerRouteTableName,
+			ResourceName: routeTable.RouteTableId,
+			Description:  propagatedRoutesPerRouteTableDesc,
+			Usage:        float64(propagatedRoutes),
+			Quota:        propagatedRoutesPerRouteTable,
+			Tags:         ec2TagsToQuotaUsageTags(routeTable.Tags),
+			Labels:       map[string]string{vpcIDLabel: aws.StringValue(routeTable.VpcId)},
+		})
+	}
+
+	return quotaUsages, nil
+}
+
+// Definitions returns the propagated routes per route table quota
+func (c *PropagatedRoutesPerRouteTableUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: propagatedRoutesPerRouteTableName, Description: propagatedRoutesPerRouteTableDesc}}
+}
+
+// staticRouteCount returns the number of routes `routes` count as
+// against the routes per route table quota. Propagated routes have a
+// limit of their own and the local routes of the VPC's CIDR blocks
+// are not counted, while routes to prefix lists count as their max
+// entries
+// https://docs.aws.amazon.com/vpc/latest/userguide/amazon-vpc-limits.html#vpc-limits-route-tables
+func staticRouteCount(routes []*ec2.Route, weights map[string]int64) int64 {
+	var count int64
+
+	for _, route := range routes {
+		if aws.StringValue(route.Origin) == propagatedRouteOrigin || aws.StringValue(route.GatewayId) == localRouteGatewayID {
+			continue
+		}
+
+		if route.DestinationPrefixListId != nil {
+			count += prefixListWeight(route.DestinationPrefixListId, weights)
+		} else {
+			count++
+		}
+	}
+
+	return count
+}
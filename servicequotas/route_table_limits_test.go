@@ -0,0 +1,159 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func (m *mockEC2Client) DescribeRouteTablesPages(input *ec2.DescribeRouteTablesInput, fn func(*ec2.DescribeRouteTablesOutput, bool) bool) error {
+	m.DescribeRouteTablesCalls++
+	fn(m.DescribeRouteTablesResponse, true)
+	return m.err
+}
+
+func testRouteTables() *ec2.DescribeRouteTablesOutput {
+	return &ec2.DescribeRouteTablesOutput{
+		RouteTables: []*ec2.RouteTable{
+			{
+				RouteTableId: aws.String("rtb-1"),
+				VpcId:        aws.String("vpc-1"),
+				Routes: []*ec2.Route{
+					{DestinationCidrBlock: aws.String("10.0.0.0/16"), GatewayId: aws.String("local"), Origin: aws.String("CreateRouteTable")},
+					{DestinationCidrBlock: aws.String("0.0.0.0/0"), NatGatewayId: aws.String("nat-1"), Origin: aws.String("CreateRoute")},
+					{DestinationPrefixListId: aws.String("pl-1"), TransitGatewayId: aws.String("tgw-1"), Origin: aws.String("CreateRoute")},
+					{DestinationCidrBlock: aws.String("192.168.0.0/24"), GatewayId: aws.String("vgw-1"), Origin: aws.String("EnableVgwRoutePropagation")},
+					{DestinationCidrBlock: aws.String("192.168.1.0/24"), GatewayId: aws.String("vgw-1"), Origin: aws.String("EnableVgwRoutePropagation")},
+				},
+				PropagatingVgws: []*ec2.PropagatingVgw{{GatewayId: aws.String("vgw-1")}},
+				Tags:            []*ec2.Tag{{Key: aws.String("team"), Value: aws.String("networking")}},
+			},
+			{
+				RouteTableId: aws.String("rtb-2"),
+				VpcId:        aws.String("vpc-1"),
+				Routes: []*ec2.Route{
+					{DestinationCidrBlock: aws.String("10.0.0.0/16"), GatewayId: aws.String("local"), Origin: aws.String("CreateRouteTable")},
+				},
+			},
+		},
+	}
+}
+
+func TestRouteTableUsageChecksWithError(t *testing.T) {
+	mockClient := &mockEC2Client{
+		err: errors.New("some err"),
+	}
+
+	testCases := []struct {
+		name  string
+		check UsageCheck
+	}{
+		{name: "RouteTablesPerVPC", check: &RouteTablesPerVPCUsageCheck{newVPCsCache(mockClient, testAccountID())}},
+		{name: "RoutesPerRouteTable", check: &RoutesPerRouteTableUsageCheck{vpcs: newVPCsCache(mockClient, testAccountID()), securityGroups: newSecurityGroupsCache(mockClient)}},
+		{name: "PropagatedRoutesPerRouteTable", check: &PropagatedRoutesPerRouteTableUsageCheck{newVPCsCache(mockClient, testAccountID())}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			usage, err := tc.check.Usage()
+
+			assert.Error(t, err)
+			assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+			assert.Nil(t, usage)
+		})
+	}
+}
+
+func TestRouteTablesPerVPCUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeVpcsResponse: &ec2.DescribeVpcsOutput{
+			Vpcs: []*ec2.Vpc{{VpcId: aws.String("vpc-1"), OwnerId: aws.String("123456789012")}, {VpcId: aws.String("vpc-2"), OwnerId: aws.String("123456789012")}},
+		},
+		DescribeRouteTablesResponse: testRouteTables(),
+	}
+
+	check := RouteTablesPerVPCUsageCheck{newVPCsCache(mockClient, testAccountID())}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         routeTablesPerVPCName,
+			ResourceName: aws.String("vpc-1"),
+			Description:  routeTablesPerVPCDesc,
+			Usage:        2,
+			Labels:       map[string]string{"vpc_id": "vpc-1"},
+		},
+		{
+			Name:         routeTablesPerVPCName,
+			ResourceName: aws.String("vpc-2"),
+			Description:  routeTablesPerVPCDesc,
+			Usage:        0,
+			Labels:       map[string]string{"vpc_id": "vpc-2"},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
+
+func TestRoutesPerRouteTableUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeRouteTablesResponse: testRouteTables(),
+		DescribeManagedPrefixListsResponse: &ec2.DescribeManagedPrefixListsOutput{
+			PrefixLists: []*ec2.ManagedPrefixList{
+				{PrefixListId: aws.String("pl-1"), MaxEntries: aws.Int64(10)},
+			},
+		},
+	}
+
+	check := RoutesPerRouteTableUsageCheck{vpcs: newVPCsCache(mockClient, testAccountID()), securityGroups: newSecurityGroupsCache(mockClient)}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         routesPerRouteTableName,
+			ResourceName: aws.String("rtb-1"),
+			Description:  routesPerRouteTableDesc,
+			Usage:        11,
+			Tags:         map[string]string{"team": "networking"},
+			Labels:       map[string]string{"vpc_id": "vpc-1"},
+		},
+		{
+			Name:         routesPerRouteTableName,
+			ResourceName: aws.String("rtb-2"),
+			Description:  routesPerRouteTableDesc,
+			Usage:        0,
+			Labels:       map[string]string{"vpc_id": "vpc-1"},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
+
+func TestPropagatedRoutesPerRouteTableUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeRouteTablesResponse: testRouteTables(),
+	}
+
+	check := PropagatedRoutesPerRouteTableUsageCheck{newVPCsCache(mockClient, testAccountID())}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         propagatedRoutesPerRouteTableName,
+			ResourceName: aws.String("rtb-1"),
+			Description:  propagatedRoutesPerRouteTableDesc,
+			Usage:        2,
+			Quota:        100,
+			Tags:         map[string]string{"team": "networking"},
+			Labels:       map[string]string{"vpc_id": "vpc-1"},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
@@ -0,0 +1,50 @@
+package servicequotas
+
+import "github.com/aws/aws-sdk-go/service/ec2"
+
+// prefixListWeights returns the max entries of every prefix list in
+// `prefixLists` by prefix list ID. A rule referencing a prefix list
+// counts as its max entries against quotas, regardless of the number
+// of entries it currently holds
+// https://docs.aws.amazon.com/vpc/latest/userguide/managed-prefix-lists.html
+func prefixListWeights(prefixLists []*ec2.ManagedPrefixList) map[string]int64 {
+	weights := map[string]int64{}
+	for _, prefixList := range prefixLists {
+		if prefixList.PrefixListId != nil && prefixList.MaxEntries != nil {
+			weights[*prefixList.PrefixListId] = *prefixList.MaxEntries
+		}
+	}
+	return weights
+}
+
+// prefixListWeight returns the weight of the prefix list `id`. Prefix
+// lists that are not visible to the account count as a single rule
+func prefixListWeight(id *string, weights map[string]int64) int64 {
+	if id != nil {
+		if weight, ok := weights[*id]; ok {
+			return weight
+		}
+	}
+	return 1
+}
+
+// securityGroupRuleCount returns the number of rules `permissions`
+// count as against the rules per security group quota. Each IPv4
+// CIDR, IPv6 CIDR and referenced security group is a rule of its own,
+// while referenced prefix lists count as their max entries
+// https://docs.aws.amazon.com/vpc/latest/userguide/amazon-vpc-limits.html#vpc-limits-security-groups
+func securityGroupRuleCount(permissions []*ec2.IpPermission, weights map[string]int64) int64 {
+	var rules int64
+
+	for _, permission := range permissions {
+		rules += int64(len(permission.IpRanges))
+		rules += int64(len(permission.Ipv6Ranges))
+		rules += int64(len(permission.UserIdGroupPairs))
+
+		for _, prefixList := range permission.PrefixListIds {
+			rules += prefixListWeight(prefixList.PrefixListId, weights)
+		}
+	}
+
+	return rules
+}
@@ -0,0 +1,141 @@
+package servicequotas
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func (m *mockEC2Client) DescribeManagedPrefixListsPages(input *ec2.DescribeManagedPrefixListsInput, fn func(*ec2.DescribeManagedPrefixListsOutput, bool) bool) error {
+	m.DescribeManagedPrefixListsCalls++
+	fn(m.DescribeManagedPrefixListsResponse, true)
+	return m.err
+}
+
+func TestPrefixListWeights(t *testing.T) {
+	prefixLists := []*ec2.ManagedPrefixList{
+		{
+			PrefixListId:   aws.String("pl-6da54004"),
+			PrefixListName: aws.String("com.amazonaws.eu-west-1.s3"),
+			OwnerId:        aws.String("AWS"),
+			MaxEntries:     nil,
+		},
+		{
+			PrefixListId:   aws.String("pl-0123456789abcdef0"),
+			PrefixListName: aws.String("office-ranges"),
+			OwnerId:        aws.String("123456789012"),
+			MaxEntries:     aws.Int64(20),
+		},
+	}
+
+	weights := prefixListWeights(prefixLists)
+
+	assert.Equal(t, map[string]int64{"pl-0123456789abcdef0": 20}, weights)
+}
+
+func TestSecurityGroupRuleCount(t *testing.T) {
+	weights := map[string]int64{
+		"pl-0123456789abcdef0": 20,
+		"pl-0fedcba9876543210": 5,
+	}
+
+	testCases := []struct {
+		name          string
+		permissions   []*ec2.IpPermission
+		expectedRules int64
+	}{
+		{
+			name:          "WithNoPermissions",
+			permissions:   []*ec2.IpPermission{},
+			expectedRules: 0,
+		},
+		{
+			name: "DefaultEgress",
+			permissions: []*ec2.IpPermission{
+				{
+					IpProtocol: aws.String("-1"),
+					IpRanges:   []*ec2.IpRange{{CidrIp: aws.String("0.0.0.0/0")}},
+					Ipv6Ranges: []*ec2.Ipv6Range{{CidrIpv6: aws.String("::/0")}},
+				},
+			},
+			expectedRules: 2,
+		},
+		{
+			name: "DefaultGroupSelfReference",
+			permissions: []*ec2.IpPermission{
+				{
+					IpProtocol: aws.String("-1"),
+					UserIdGroupPairs: []*ec2.UserIdGroupPair{
+						{GroupId: aws.String("sg-0a1b2c3d4e5f67890"), UserId: aws.String("123456789012")},
+					},
+				},
+			},
+			expectedRules: 1,
+		},
+		{
+			name: "LoadBalancerWithMultiplePorts",
+			permissions: []*ec2.IpPermission{
+				{
+					IpProtocol: aws.String("tcp"),
+					FromPort:   aws.Int64(80),
+					ToPort:     aws.Int64(80),
+					IpRanges: []*ec2.IpRange{
+						{CidrIp: aws.String("10.0.0.0/16")},
+						{CidrIp: aws.String("10.1.0.0/16")},
+					},
+					Ipv6Ranges: []*ec2.Ipv6Range{{CidrIpv6: aws.String("2001:db8::/32")}},
+				},
+				{
+					IpProtocol: aws.String("tcp"),
+					FromPort:   aws.Int64(443),
+					ToPort:     aws.Int64(443),
+					IpRanges: []*ec2.IpRange{
+						{CidrIp: aws.String("10.0.0.0/16")},
+						{CidrIp: aws.String("10.1.0.0/16")},
+					},
+					Ipv6Ranges: []*ec2.Ipv6Range{{CidrIpv6: aws.String("2001:db8::/32")}},
+				},
+			},
+			expectedRules: 6,
+		},
+		{
+			name: "EKSClusterWithPrefixLists",
+			permissions: []*ec2.IpPermission{
+				{
+					IpProtocol: aws.String("tcp"),
+					FromPort:   aws.Int64(443),
+					ToPort:     aws.Int64(443),
+					UserIdGroupPairs: []*ec2.UserIdGroupPair{
+						{GroupId: aws.String("sg-0afb91d177e53ae1d"), Description: aws.String("Allow workers to communicate with the control plane.")},
+						{GroupId: aws.String("sg-0bfb91d177e53ae1d"), UserId: aws.String("210987654321"), VpcPeeringConnectionId: aws.String("pcx-1a2b3c4d")},
+					},
+					PrefixListIds: []*ec2.PrefixListId{
+						{PrefixListId: aws.String("pl-0123456789abcdef0"), Description: aws.String("Office ranges")},
+						{PrefixListId: aws.String("pl-0fedcba9876543210"), Description: aws.String("VPN ranges")},
+					},
+				},
+			},
+			expectedRules: 27,
+		},
+		{
+			name: "WithUnknownPrefixList",
+			permissions: []*ec2.IpPermission{
+				{
+					IpProtocol:    aws.String("tcp"),
+					FromPort:      aws.Int64(443),
+					ToPort:        aws.Int64(443),
+					PrefixListIds: []*ec2.PrefixListId{{PrefixListId: aws.String("pl-shared00000000000")}},
+				},
+			},
+			expectedRules: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedRules, securityGroupRuleCount(tc.permissions, weights))
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package servicequotas
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// securityGroupsCache caches the security groups and the managed
+// prefix lists their rules reference, so usage checks counting
+// security groups or rules don't describe them separately
+type securityGroupsCache struct {
+	client                    ec2iface.EC2API
+	ttl                       time.Duration
+	lock                      sync.Mutex
+	securityGroups            []*ec2.SecurityGroup
+	securityGroupsLastRefresh time.Time
+	prefixLists               []*ec2.ManagedPrefixList
+	prefixListsLastRefresh    time.Time
+}
+
+func newSecurityGroupsCache(client ec2iface.EC2API) *securityGroupsCache {
+	return &securityGroupsCache{client: client, ttl: instancesCacheTTL}
+}
+
+// SecurityGroups returns the security groups, describing them if the
+// cached security groups are older than the cache's TTL, or an error
+func (c *securityGroupsCache) SecurityGroups() ([]*ec2.SecurityGroup, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.securityGroups != nil && time.Since(c.securityGroupsLastRefresh) < c.ttl {
+		return c.securityGroups, nil
+	}
+
+	securityGroups, err := describeSecurityGroups(c.client)
+	if err != nil {
+		return nil, err
+	}
+
+	c.securityGroups = securityGroups
+	c.securityGroupsLastRefresh = time.Now()
+	return securityGroups, nil
+}
+
+// PrefixLists returns the managed prefix lists visible to the
+// account, describing them if the cached prefix lists are older than
+// the cache's TTL, or an error
+func (c *securityGroupsCache) PrefixLists() ([]*ec2.ManagedPrefixList, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.prefixLists != nil && time.Since(c.prefixListsLastRefresh) < c.ttl {
+		return c.prefixLists, nil
+	}
+
+	prefixLists, err := describeManagedPrefixLists(c.client)
+	if err != nil {
+		return nil, err
+	}
+
+	c.prefixLists = prefixLists
+	c.prefixListsLastRefresh = time.Now()
+	return prefixLists, nil
+}
+
+// PrefixListWeights returns the weights of the managed prefix lists
+// by prefix list ID, see prefixListWeights, or an error
+func (c *securityGroupsCache) PrefixListWeights() (map[string]int64, error) {
+	prefixLists, err := c.PrefixLists()
+	if err != nil {
+		return nil, err
+	}
+	return prefixListWeights(prefixLists), nil
+}
+
+func describeSecurityGroups(client ec2iface.EC2API) ([]*ec2.SecurityGroup, error) {
+	securityGroups := []*ec2.SecurityGroup{}
+	err := client.DescribeSecurityGroupsPages(&ec2.DescribeSecurityGroupsInput{},
+		func(page *ec2.DescribeSecurityGroupsOutput, lastPage bool) bool {
+			if page != nil {
+				securityGroups = append(securityGroups, page.SecurityGroups...)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return securityGroups, nil
+}
+
+func describeManagedPrefixLists(client ec2iface.EC2API) ([]*ec2.ManagedPrefixList, error) {
+	prefixLists := []*ec2.ManagedPrefixList{}
+	err := client.DescribeManagedPrefixListsPages(&ec2.DescribeManagedPrefixListsInput{},
+		func(page *ec2.DescribeManagedPrefixListsOutput, lastPage bool) bool {
+			if page != nil {
+				prefixLists = append(prefixLists, page.PrefixLists...)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return prefixLists, nil
+}
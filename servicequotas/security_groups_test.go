@@ -0,0 +1,66 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityGroupsCacheWithError(t *testing.T) {
+	mockClient := &mockEC2Client{err: errors.New("some err")}
+	cache := newSecurityGroupsCache(mockClient)
+
+	securityGroups, err := cache.SecurityGroups()
+
+	assert.Error(t, err)
+	assert.Nil(t, securityGroups)
+
+	weights, err := cache.PrefixListWeights()
+
+	assert.Error(t, err)
+	assert.Nil(t, weights)
+}
+
+func TestSecurityGroupsCache(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeSecurityGroupsResponse: &ec2.DescribeSecurityGroupsOutput{
+			SecurityGroups: []*ec2.SecurityGroup{
+				{GroupId: aws.String("sg-1")},
+				{GroupId: aws.String("sg-2")},
+			},
+		},
+		DescribeManagedPrefixListsResponse: &ec2.DescribeManagedPrefixListsOutput{
+			PrefixLists: []*ec2.ManagedPrefixList{
+				{PrefixListId: aws.String("pl-1"), MaxEntries: aws.Int64(20)},
+			},
+		},
+	}
+	cache := newSecurityGroupsCache(mockClient)
+
+	for i := 0; i < 3; i++ {
+		securityGroups, err := cache.SecurityGroups()
+
+		assert.NoError(t, err)
+		assert.Len(t, securityGroups, 2)
+
+		weights, err := cache.PrefixListWeights()
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"pl-1": 20}, weights)
+	}
+	assert.Equal(t, 1, mockClient.DescribeSecurityGroupsCalls)
+	assert.Equal(t, 1, mockClient.DescribeManagedPrefixListsCalls)
+
+	cache.securityGroupsLastRefresh = time.Now().Add(-instancesCacheTTL)
+	_, err := cache.SecurityGroups()
+	assert.NoError(t, err)
+	_, err = cache.PrefixLists()
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, mockClient.DescribeSecurityGroupsCalls)
+	assert.Equal(t, 1, mockClient.DescribeManagedPrefixListsCalls)
+}
@@ -5,9 +5,14 @@ package servicequotas
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
@@ -15,6 +20,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/lambda"
 	awsservicequotas "github.com/aws/aws-sdk-go/service/servicequotas"
 	"github.com/aws/aws-sdk-go/service/servicequotas/servicequotasiface"
+	"github.com/aws/aws-sdk-go/service/sts"
 	logging "github.com/sirupsen/logrus"
 )
 
@@ -27,31 +33,145 @@ var (
 )
 
 func allServices() []string {
-	return []string{"ec2", "vpc"}
+	return []string{"ec2", "vpc", "ebs"}
 }
 
 // UsageCheck is an interface for retrieving service quota usage
 type UsageCheck interface {
 	// Usage returns slice of QuotaUsage or an error
 	Usage() ([]QuotaUsage, error)
+	// Definitions returns the quotas that Usage reports on
+	Definitions() []QuotaDefinition
 }
 
-func newUsageChecks(c client.ConfigProvider, cfgs ...*aws.Config) (map[string]UsageCheck, []UsageCheck) {
+// namedQuotasUsageCheck is a UsageCheck for quotas that are matched
+// by name rather than by quota code, such as the quotas per instance
+// family. It is given the quotas of its service when they are listed
+// for the usage checks matched by quota code, so it is not run in AWS
+// china either unless it is a defaultQuotasUsageCheck
+type namedQuotasUsageCheck interface {
+	UsageCheck
+	// serviceCode returns the code of the service of the quotas
+	serviceCode() string
+	// setQuotas sets the quotas listed for the service
+	setQuotas(quotas []*awsservicequotas.ServiceQuota)
+}
+
+// defaultQuotasUsageCheck is a namedQuotasUsageCheck whose quotas
+// fall back to their default value when they have not been listed, so
+// unlike other namedQuotasUsageChecks it is run in AWS china
+type defaultQuotasUsageCheck interface {
+	namedQuotasUsageCheck
+	// quota returns the value of the listed quota `name` or
+	// `fallback` if it has not been listed
+	quota(name string, fallback float64) float64
+}
+
+// requiresListedQuotas returns whether `check` can only be run when
+// the quotas are listed, which they are not in AWS china
+func requiresListedQuotas(check UsageCheck) bool {
+	if _, ok := check.(defaultQuotasUsageCheck); ok {
+		return false
+	}
+	_, ok := check.(namedQuotasUsageCheck)
+	return ok
+}
+
+// listedQuotas implements the setQuotas and quota methods of
+// defaultQuotasUsageCheck for usage checks embedding it, keeping the
+// values of the listed quotas by their case-insensitive name
+type listedQuotas struct {
+	lock   sync.Mutex
+	quotas map[string]float64
+}
+
+// setQuotas sets the values of the listed `quotas` by name
+func (q *listedQuotas) setQuotas(quotas []*awsservicequotas.ServiceQuota) {
+	values := map[string]float64{}
+	for _, quota := range quotas {
+		if quota.QuotaName != nil && quota.Value != nil {
+			values[strings.ToLower(*quota.QuotaName)] = *quota.Value
+		}
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.quotas = values
+}
+
+// quota returns the value of the listed quota `name` or `fallback` if
+// it has not been listed
+func (q *listedQuotas) quota(name string, fallback float64) float64 {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if value, ok := q.quotas[strings.ToLower(name)]; ok {
+		return value
+	}
+	return fallback
+}
+
+func newUsageChecks(options Options, c client.ConfigProvider, cfgs ...*aws.Config) (map[string]UsageCheck, []UsageCheck) {
 	// all clients that will be used by the usage checks
 	ec2Client := ec2.New(c, cfgs...)
 	autoscalingClient := autoscaling.New(c, cfgs...)
 	lambdaClient := lambda.New(c, cfgs...)
+	stsClient := sts.New(c, cfgs...)
+
+	// caches shared by the usage checks describing the same resources
+	instances := newInstancesCache(ec2Client)
+	instanceTypes := newInstanceTypeCatalog(ec2Client)
+	pendingSpot := newPendingSpotCache(ec2Client, instanceTypes)
+	networkInterfaces := newNetworkInterfacesCache(ec2Client)
+	securityGroups := newSecurityGroupsCache(ec2Client)
+	volumes := newVolumesCache(ec2Client)
+	accountID := newAccountIDCache(stsClient)
+	vpcs := newVPCsCache(ec2Client, accountID)
 
 	serviceQuotasUsageChecks := map[string]UsageCheck{
-		"L-0EA8095F": &RulesPerSecurityGroupUsageCheck{ec2Client},
-		"L-2AFB9258": &SecurityGroupsPerENIUsageCheck{ec2Client},
-		"L-E79EC296": &SecurityGroupsPerRegionUsageCheck{ec2Client},
-		"L-34B43A08": &StandardSpotInstanceRequestsUsageCheck{ec2Client},
-		"L-1216C47A": &RunningOnDemandStandardInstancesUsageCheck{ec2Client},
+		"L-0EA8095F": &RulesPerSecurityGroupUsageCheck{securityGroups},
+		"L-2AFB9258": &SecurityGroupsPerENIUsageCheck{networkInterfaces},
+		"L-E79EC296": &SecurityGroupsPerRegionUsageCheck{securityGroups},
+		"L-0263D0A3": &ElasticIPsUsageCheck{ec2Client},
+		"L-F678F1CE": &VPCsPerRegionUsageCheck{vpcs},
+		"L-407747CB": &SubnetsPerVPCUsageCheck{vpcs},
+		"L-A4707A72": &InternetGatewaysPerRegionUsageCheck{ec2Client},
+		"L-FE5A380F": &NATGatewaysPerAZUsageCheck{client: ec2Client, vpcs: vpcs},
+		"L-45FE3B85": &EgressOnlyInternetGatewaysPerRegionUsageCheck{ec2Client},
+		"L-589F43AA": &RouteTablesPerVPCUsageCheck{vpcs},
+		"L-93826ACB": &RoutesPerRouteTableUsageCheck{vpcs: vpcs, securityGroups: securityGroups},
+		"L-B4A6D682": &NetworkACLsPerVPCUsageCheck{client: ec2Client, vpcs: vpcs},
+		"L-2AEEBF1A": &RulesPerNetworkACLUsageCheck{ec2Client},
+		"L-7E9ECCDB": &VPCPeeringConnectionsPerVPCUsageCheck{client: ec2Client, vpcs: vpcs},
+		"L-29B6F2EB": &InterfaceVPCEndpointsPerVPCUsageCheck{client: ec2Client, vpcs: vpcs},
+		"L-1B52E74A": &GatewayVPCEndpointsPerRegionUsageCheck{ec2Client},
+		"L-A2478D36": &TransitGatewaysPerAccountUsageCheck{client: ec2Client, accountID: accountID},
+		"L-E0233F82": &AttachmentsPerTransitGatewayUsageCheck{client: ec2Client, accountID: accountID},
+		"L-B665C33B": &AMIsPerRegionUsageCheck{client: ec2Client},
+		"L-0E3CBAB9": &AMIsPerRegionUsageCheck{client: ec2Client, public: true},
+		"L-DF5E4CA3": &NetworkInterfacesPerRegionUsageCheck{networkInterfaces: networkInterfaces, breakdown: options.NetworkInterfacesBreakdown},
+	}
+	for code, check := range instanceVCPUsUsageChecks(options, instances, instanceTypes, pendingSpot) {
+		serviceQuotasUsageChecks[code] = check
+	}
+	for code, check := range ebsUsageChecks(ec2Client, volumes) {
+		serviceQuotasUsageChecks[code] = check
 	}
 
 	otherUsageChecks := []UsageCheck{
-		&AvailableIpsPerSubnetUsageCheck{ec2Client},
+		&AvailableIpsPerSubnetUsageCheck{vpcs: vpcs, networkInterfaces: networkInterfaces, prefixDelegation: options.SubnetPrefixDelegation},
+		&AvailableIpsPerVPCUsageCheck{vpcs},
+		&PropagatedRoutesPerRouteTableUsageCheck{vpcs},
+		&SecurityGroupsPerVPCEndpointUsageCheck{ec2Client},
+		&PrefixListEntriesUsageCheck{client: ec2Client, accountID: accountID},
+		&PrefixListsPerRegionUsageCheck{client: ec2Client, accountID: accountID},
+		&TransitGatewayRouteTablesUsageCheck{client: ec2Client, accountID: accountID},
+		&RulesPerENIUsageCheck{securityGroups: securityGroups, networkInterfaces: networkInterfaces},
+		&LaunchTemplatesUsageCheck{client: ec2Client},
+		&KeyPairsPerRegionUsageCheck{client: ec2Client},
+		&DedicatedHostsUsageCheck{client: ec2Client},
+		&InstanceNetworkInterfacesUsageCheck{instances: instances, instanceTypes: instanceTypes},
+		&InstanceAttachmentsUsageCheck{instances: instances, instanceTypes: instanceTypes},
 		&ASGUsageCheck{autoscalingClient},
 		&LambdaConcurrentExecutionsLimitCheck{lambdaClient},
 	}
@@ -59,6 +179,19 @@ func newUsageChecks(c client.ConfigProvider, cfgs ...*aws.Config) (map[string]Us
 	return serviceQuotasUsageChecks, otherUsageChecks
 }
 
+// QuotaDefinition describes a quota or piece of availability
+// information reported by a UsageCheck, independently of its usage
+type QuotaDefinition struct {
+	// Name is the name of the quota, matching QuotaUsage.Name
+	Name string
+	// Description is the description of the quota, matching
+	// QuotaUsage.Description
+	Description string
+	// Breakdown is set when the usage breaks down the usage of
+	// another quota of the check, it has no limit of its own
+	Breakdown bool
+}
+
 // QuotaUsage represents service quota usage
 type QuotaUsage struct {
 	// Name is the name of the quota (eg. spot_instance_requests)
@@ -80,6 +213,10 @@ type QuotaUsage struct {
 
 	// Tags are the metadata associated with the resource in form of key, value pairs
 	Tags map[string]string
+	// Labels are additional dimensions of the quota usage (eg. the
+	// VPC ID and availability zone of aggregated IP usage), exported
+	// as labels named after the keys
+	Labels map[string]string
 }
 
 // Identifier for the service quota. Either the resource name in case
@@ -91,6 +228,19 @@ func (q QuotaUsage) Identifier() string {
 	return q.Name
 }
 
+// CheckResult is the outcome of running a single usage check
+type CheckResult struct {
+	// Definitions are the quotas the usage check reports on
+	Definitions []QuotaDefinition
+	// Usages are the quotas and usage returned by the usage check,
+	// nil if the check failed
+	Usages []QuotaUsage
+	// Err is the error returned by the usage check
+	Err error
+	// Time is when the usage check completed
+	Time time.Time
+}
+
 // ServiceQuotas is an implementation for retrieving service quotas
 // and their limits
 type ServiceQuotas struct {
@@ -106,29 +256,64 @@ type ServiceQuotas struct {
 // quotas and usage
 type QuotasInterface interface {
 	QuotasAndUsage() ([]QuotaUsage, error)
+	CheckResults() ([]CheckResult, error)
+	Definitions() []QuotaDefinition
+}
+
+// Options configures the ServiceQuotas created by
+// NewServiceQuotasWithOptions
+type Options struct {
+	// RoleARN is the ARN of the IAM role assumed to retrieve quotas
+	// and usage. The session credentials are used directly when empty
+	RoleARN string
+	// Checks restricts the usage checks to the ones reporting any of
+	// the quota names. All usage checks are enabled when empty
+	Checks []string
+	// SubnetPrefixDelegation additionally reports the usage of
+	// subnets in /28 prefixes, as assigned to network interfaces
+	// with prefix delegation
+	SubnetPrefixDelegation bool
+	// VCPUBreakdown additionally reports the vCPU usage of every
+	// vCPU quota per instance family and availability zone
+	VCPUBreakdown bool
+	// VCPUBreakdownTags are the instance tags the vCPU usage
+	// breakdown is additionally split by
+	VCPUBreakdownTags []string
+	// NetworkInterfacesBreakdown additionally reports the network
+	// interfaces per region per interface type and requester
+	NetworkInterfacesBreakdown bool
 }
 
 // NewServiceQuotas creates a ServiceQuotas for `region` and `profile`
 // or returns an error. Note that the ServiceQuotas will only return
 // usage and quotas for the service quotas with implemented usage checks
 func NewServiceQuotas(region, profile string) (QuotasInterface, error) {
+	return NewServiceQuotasWithOptions(region, profile, Options{})
+}
+
+// NewServiceQuotasWithOptions creates a ServiceQuotas for `region`
+// and `profile` configured with `options` or returns an error
+func NewServiceQuotasWithOptions(region, profile string, options Options) (QuotasInterface, error) {
 	validRegion, isChina := isValidRegion(region)
 	if !validRegion {
 		return nil, fmt.Errorf("%w: failed to create ServiceQuotas", ErrInvalidRegion)
 	}
 
-	opts := session.Options{}
-	if profile != "" {
-		opts = session.Options{Profile: profile}
-	}
-
-	awsSession, err := session.NewSessionWithOptions(opts)
+	awsSession, err := newSession(profile)
 	if err != nil {
 		return nil, err
 	}
 
-	quotasService := awsservicequotas.New(awsSession, aws.NewConfig().WithRegion(region))
-	serviceQuotasChecks, otherChecks := newUsageChecks(awsSession, aws.NewConfig().WithRegion(region))
+	cfg := aws.NewConfig().WithRegion(region)
+	if options.RoleARN != "" {
+		cfg = cfg.WithCredentials(stscreds.NewCredentials(awsSession, options.RoleARN))
+	}
+
+	quotasService := awsservicequotas.New(awsSession, cfg)
+	serviceQuotasChecks, otherChecks := newUsageChecks(options, awsSession, cfg)
+	if len(options.Checks) > 0 {
+		serviceQuotasChecks, otherChecks = filterUsageChecks(serviceQuotasChecks, otherChecks, options.Checks)
+	}
 
 	if isChina {
 		logging.Warn("AWS china currently doesn't support service quotas, disabling...")
@@ -145,6 +330,77 @@ func NewServiceQuotas(region, profile string) (QuotasInterface, error) {
 	return quotas, nil
 }
 
+func newSession(profile string) (*session.Session, error) {
+	opts := session.Options{}
+	if profile != "" {
+		opts = session.Options{Profile: profile}
+	}
+
+	return session.NewSessionWithOptions(opts)
+}
+
+// AccountID returns the ID of the AWS account of the credentials of
+// `profile` or an error
+func AccountID(region, profile string) (string, error) {
+	awsSession, err := newSession(profile)
+	if err != nil {
+		return "", err
+	}
+
+	stsClient := sts.New(awsSession, aws.NewConfig().WithRegion(region))
+	identity, err := stsClient.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", err
+	}
+
+	return *identity.Account, nil
+}
+
+// filterUsageChecks returns the usage checks reporting any of the
+// quotas named in `names`
+func filterUsageChecks(serviceQuotasChecks map[string]UsageCheck, otherChecks []UsageCheck, names []string) (map[string]UsageCheck, []UsageCheck) {
+	enabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		enabled[name] = true
+	}
+
+	reportsEnabledQuota := func(check UsageCheck) bool {
+		for _, definition := range check.Definitions() {
+			if enabled[definition.Name] {
+				return true
+			}
+		}
+		return false
+	}
+
+	filteredServiceQuotasChecks := map[string]UsageCheck{}
+	for code, check := range serviceQuotasChecks {
+		if reportsEnabledQuota(check) {
+			filteredServiceQuotasChecks[code] = check
+		}
+	}
+
+	filteredOtherChecks := []UsageCheck{}
+	for _, check := range otherChecks {
+		if reportsEnabledQuota(check) {
+			filteredOtherChecks = append(filteredOtherChecks, check)
+		}
+	}
+
+	return filteredServiceQuotasChecks, filteredOtherChecks
+}
+
+// RoleARN returns the ARN of the IAM role named `roleName` in
+// `accountID` for the partition of `region` or an error if the region
+// is invalid
+func RoleARN(region, accountID, roleName string) (string, error) {
+	partition, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrInvalidRegion, region)
+	}
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", partition.ID(), accountID, roleName), nil
+}
+
 func isValidRegion(region string) (bool, bool) {
 	for _, partition := range endpoints.DefaultPartitions() {
 		_, ok := partition.Regions()[region]
@@ -155,27 +411,42 @@ func isValidRegion(region string) (bool, bool) {
 	return false, false
 }
 
-func (s *ServiceQuotas) quotasForService(service string) ([]QuotaUsage, error) {
-	serviceQuotaUsages := []QuotaUsage{}
-	var usageErr error
+// breakdownNames returns the names of the breakdowns in
+// `definitions`, whose usages are not given the quota of the check
+func breakdownNames(definitions []QuotaDefinition) map[string]bool {
+	names := map[string]bool{}
+	for _, definition := range definitions {
+		if definition.Breakdown {
+			names[definition.Name] = true
+		}
+	}
+	return names
+}
+
+func (s *ServiceQuotas) quotasForService(service string) ([]CheckResult, error) {
+	results := []CheckResult{}
+	quotas := []*awsservicequotas.ServiceQuota{}
 
 	params := &awsservicequotas.ListServiceQuotasInput{ServiceCode: aws.String(service)}
 	err := s.quotasService.ListServiceQuotasPages(params,
 		func(page *awsservicequotas.ListServiceQuotasOutput, lastPage bool) bool {
 			if page != nil {
+				quotas = append(quotas, page.Quotas...)
 				for _, quota := range page.Quotas {
 					if check, ok := s.serviceQuotasUsageChecks[*quota.QuotaCode]; ok {
-						quotaUsages, err := check.Usage()
-						if err != nil {
-							usageErr = err
-							// stop paging when an error is encountered
-							return true
-						}
-
-						for _, quotaUsage := range quotaUsages {
-							quotaUsage.Quota = *quota.Value
-							serviceQuotaUsages = append(serviceQuotaUsages, quotaUsage)
+						result := runCheck(check)
+						if result.Err == nil {
+							breakdowns := breakdownNames(result.Definitions)
+							quotaUsages := make([]QuotaUsage, 0, len(result.Usages))
+							for _, quotaUsage := range result.Usages {
+								if !breakdowns[quotaUsage.Name] {
+									quotaUsage.Quota = *quota.Value
+								}
+								quotaUsages = append(quotaUsages, quotaUsage)
+							}
+							result.Usages = quotaUsages
 						}
+						results = append(results, result)
 					}
 				}
 			}
@@ -186,40 +457,95 @@ func (s *ServiceQuotas) quotasForService(service string) ([]QuotaUsage, error) {
 		return nil, fmt.Errorf("%w: %s", ErrFailedToListQuotas, err)
 	}
 
-	if usageErr != nil {
-		return nil, usageErr
+	for _, check := range s.otherUsageChecks {
+		if namedCheck, ok := check.(namedQuotasUsageCheck); ok && namedCheck.serviceCode() == service {
+			namedCheck.setQuotas(quotas)
+		}
 	}
 
-	return serviceQuotaUsages, nil
+	return results, nil
 }
 
-// QuotasAndUsage returns a slice of `QuotaUsage` or an error
-func (s *ServiceQuotas) QuotasAndUsage() ([]QuotaUsage, error) {
-	allQuotaUsages := []QuotaUsage{}
+func runCheck(check UsageCheck) CheckResult {
+	usages, err := check.Usage()
+	if err != nil {
+		usages = nil
+	}
+
+	return CheckResult{
+		Definitions: check.Definitions(),
+		Usages:      usages,
+		Err:         err,
+		Time:        time.Now(),
+	}
+}
+
+// CheckResults runs all enabled usage checks and returns their
+// results. An error is only returned if the quotas could not be
+// listed, failing usage checks are reported in their CheckResult
+func (s *ServiceQuotas) CheckResults() ([]CheckResult, error) {
+	results := []CheckResult{}
 
 	if !s.isAwsChina {
 		for _, service := range allServices() {
-			serviceQuotas, err := s.quotasForService(service)
+			serviceResults, err := s.quotasForService(service)
 			if err != nil {
 				return nil, err
 			}
-
-			for _, quota := range serviceQuotas {
-				allQuotaUsages = append(allQuotaUsages, quota)
-			}
+			results = append(results, serviceResults...)
 		}
 	}
 
 	for _, check := range s.otherUsageChecks {
-		quotas, err := check.Usage()
-		if err != nil {
-			return nil, err
+		if s.isAwsChina && requiresListedQuotas(check) {
+			continue
 		}
+		results = append(results, runCheck(check))
+	}
 
-		for _, quota := range quotas {
-			allQuotaUsages = append(allQuotaUsages, quota)
+	return results, nil
+}
+
+// QuotasAndUsage returns a slice of `QuotaUsage` or an error
+func (s *ServiceQuotas) QuotasAndUsage() ([]QuotaUsage, error) {
+	results, err := s.CheckResults()
+	if err != nil {
+		return nil, err
+	}
+
+	allQuotaUsages := []QuotaUsage{}
+	for _, result := range results {
+		if result.Err != nil {
+			return nil, result.Err
 		}
+		allQuotaUsages = append(allQuotaUsages, result.Usages...)
 	}
 
 	return allQuotaUsages, nil
 }
+
+// Definitions returns the definitions of all quotas reported by the
+// enabled usage checks sorted by name. Checks relying on the service
+// quotas API, including the ones matching quotas by name, are not
+// included in AWS china
+func (s *ServiceQuotas) Definitions() []QuotaDefinition {
+	definitions := []QuotaDefinition{}
+
+	if !s.isAwsChina {
+		for _, check := range s.serviceQuotasUsageChecks {
+			definitions = append(definitions, check.Definitions()...)
+		}
+	}
+
+	for _, check := range s.otherUsageChecks {
+		if s.isAwsChina && requiresListedQuotas(check) {
+			continue
+		}
+		definitions = append(definitions, check.Definitions()...)
+	}
+
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Name < definitions[j].Name
+	})
+	return definitions
+}
@@ -31,14 +31,19 @@ func (m *mockServiceQuotasClient) ListServiceQuotasPages(input *awsservicequotas
 }
 
 type UsageCheckMock struct {
-	err    error
-	usages []QuotaUsage
+	err         error
+	usages      []QuotaUsage
+	definitions []QuotaDefinition
 }
 
 func (m *UsageCheckMock) Usage() ([]QuotaUsage, error) {
 	return m.usages, m.err
 }
 
+func (m *UsageCheckMock) Definitions() []QuotaDefinition {
+	return m.definitions
+}
+
 func TestQuotasAndUsageWithError(t *testing.T) {
 	mockClient := &mockServiceQuotasClient{
 		err:                       errors.New("some err"),
@@ -163,13 +168,106 @@ func TestQuotasAndUsage(t *testing.T) {
 		},
 	}
 
-	expectedServiceQuotasAPICalls := 2
+	expectedServiceQuotasAPICalls := 3
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedServiceQuotasAPICalls, mockClient.timesCalled)
 	assert.Equal(t, expectedQuotasAndUsage, actualQuotasAndUsage)
 }
 
+func TestCheckResults(t *testing.T) {
+	mockClient := &mockServiceQuotasClient{
+		serviceName: "ec2",
+		ListServiceQuotasResponse: &awsservicequotas.ListServiceQuotasOutput{
+			Quotas: []*awsservicequotas.ServiceQuota{
+				{
+					QuotaCode: aws.String("L-1234"),
+					Value:     aws.Float64(15),
+				},
+				{
+					QuotaCode: aws.String("L-5678"),
+					Value:     aws.Float64(2),
+				},
+			},
+		},
+	}
+
+	expectedErr := errors.New("some err")
+	failingCheck := &UsageCheckMock{
+		err:         expectedErr,
+		usages:      []QuotaUsage{{Name: "failing_check"}},
+		definitions: []QuotaDefinition{{Name: "failing_check"}},
+	}
+	serviceQuotasCheck := &UsageCheckMock{
+		usages:      []QuotaUsage{{Name: "some_check", Usage: 1}},
+		definitions: []QuotaDefinition{{Name: "some_check"}},
+	}
+	otherCheck := &UsageCheckMock{
+		usages:      []QuotaUsage{{Name: "other_check", Usage: 3, Quota: 4}},
+		definitions: []QuotaDefinition{{Name: "other_check"}},
+	}
+
+	serviceQuotas := ServiceQuotas{
+		quotasService: mockClient,
+		serviceQuotasUsageChecks: map[string]UsageCheck{
+			"L-1234": failingCheck,
+			"L-5678": serviceQuotasCheck,
+		},
+		otherUsageChecks: []UsageCheck{otherCheck},
+	}
+	results, err := serviceQuotas.CheckResults()
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+
+	assert.Equal(t, expectedErr, results[0].Err)
+	assert.Nil(t, results[0].Usages)
+	assert.Equal(t, failingCheck.definitions, results[0].Definitions)
+
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, []QuotaUsage{{Name: "some_check", Usage: 1, Quota: 2}}, results[1].Usages)
+	assert.Equal(t, serviceQuotasCheck.definitions, results[1].Definitions)
+	// the usages returned by the check are not modified
+	assert.Equal(t, float64(0), serviceQuotasCheck.usages[0].Quota)
+
+	assert.NoError(t, results[2].Err)
+	assert.Equal(t, otherCheck.usages, results[2].Usages)
+	assert.False(t, results[2].Time.IsZero())
+}
+
+func TestCheckResultsWithBreakdown(t *testing.T) {
+	mockClient := &mockServiceQuotasClient{
+		serviceName: "ec2",
+		ListServiceQuotasResponse: &awsservicequotas.ListServiceQuotasOutput{
+			Quotas: []*awsservicequotas.ServiceQuota{{QuotaCode: aws.String("L-1234"), Value: aws.Float64(5)}},
+		},
+	}
+	check := &UsageCheckMock{
+		usages: []QuotaUsage{
+			{Name: "some_check", Usage: 2},
+			{Name: "some_check_breakdown", ResourceName: aws.String("first"), Usage: 1},
+			{Name: "some_check_breakdown", ResourceName: aws.String("second"), Usage: 1},
+		},
+		definitions: []QuotaDefinition{{Name: "some_check"}, {Name: "some_check_breakdown", Breakdown: true}},
+	}
+
+	serviceQuotas := ServiceQuotas{
+		quotasService:            mockClient,
+		serviceQuotasUsageChecks: map[string]UsageCheck{"L-1234": check},
+	}
+	results, err := serviceQuotas.CheckResults()
+
+	expectedUsages := []QuotaUsage{
+		{Name: "some_check", Usage: 2, Quota: 5},
+		{Name: "some_check_breakdown", ResourceName: aws.String("first"), Usage: 1},
+		{Name: "some_check_breakdown", ResourceName: aws.String("second"), Usage: 1},
+	}
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, expectedUsages, results[0].Usages)
+}
+
 func TestQuotasAndUsageChina(t *testing.T) {
 
 	// This won't be called as aws china doesn't support service quotas currently.
@@ -244,6 +342,225 @@ func TestQuotasAndUsageChina(t *testing.T) {
 	assert.Equal(t, expectedQuotasAndUsage, actualQuotasAndUsage)
 }
 
+type namedQuotasUsageCheckMock struct {
+	UsageCheckMock
+	quotas []*awsservicequotas.ServiceQuota
+}
+
+func (m *namedQuotasUsageCheckMock) serviceCode() string {
+	return "ec2"
+}
+
+func (m *namedQuotasUsageCheckMock) setQuotas(quotas []*awsservicequotas.ServiceQuota) {
+	m.quotas = quotas
+}
+
+func TestCheckResultsWithNamedQuotasUsageCheck(t *testing.T) {
+	quotas := []*awsservicequotas.ServiceQuota{
+		{QuotaCode: aws.String("L-1234"), QuotaName: aws.String("Running Dedicated m5 Hosts"), Value: aws.Float64(2)},
+	}
+	mockClient := &mockServiceQuotasClient{
+		serviceName:               "ec2",
+		ListServiceQuotasResponse: &awsservicequotas.ListServiceQuotasOutput{Quotas: quotas},
+	}
+
+	testCases := []struct {
+		name            string
+		isAwsChina      bool
+		expectedResults int
+		expectedQuotas  []*awsservicequotas.ServiceQuota
+	}{
+		{
+			name:            "WithServiceQuotas",
+			expectedResults: 1,
+			expectedQuotas:  quotas,
+		},
+		{
+			name:            "ForAwsChina",
+			isAwsChina:      true,
+			expectedResults: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			namedCheck := &namedQuotasUsageCheckMock{
+				UsageCheckMock: UsageCheckMock{definitions: []QuotaDefinition{{Name: "named_check"}}},
+			}
+			serviceQuotas := ServiceQuotas{
+				quotasService:    mockClient,
+				isAwsChina:       tc.isAwsChina,
+				otherUsageChecks: []UsageCheck{namedCheck},
+			}
+			results, err := serviceQuotas.CheckResults()
+
+			assert.NoError(t, err)
+			assert.Len(t, results, tc.expectedResults)
+			assert.Len(t, serviceQuotas.Definitions(), tc.expectedResults)
+			assert.Equal(t, tc.expectedQuotas, namedCheck.quotas)
+		})
+	}
+}
+
+type defaultQuotasUsageCheckMock struct {
+	namedQuotasUsageCheckMock
+	listedQuotas
+}
+
+func (m *defaultQuotasUsageCheckMock) setQuotas(quotas []*awsservicequotas.ServiceQuota) {
+	m.namedQuotasUsageCheckMock.setQuotas(quotas)
+	m.listedQuotas.setQuotas(quotas)
+}
+
+func TestCheckResultsWithDefaultQuotasUsageCheck(t *testing.T) {
+	quotas := []*awsservicequotas.ServiceQuota{
+		{QuotaCode: aws.String("L-1234"), QuotaName: aws.String("Key pairs per Region"), Value: aws.Float64(6000)},
+	}
+	mockClient := &mockServiceQuotasClient{
+		serviceName:               "ec2",
+		ListServiceQuotasResponse: &awsservicequotas.ListServiceQuotasOutput{Quotas: quotas},
+	}
+
+	testCases := []struct {
+		name          string
+		isAwsChina    bool
+		expectedQuota float64
+	}{
+		{
+			name:          "WithServiceQuotas",
+			expectedQuota: 6000,
+		},
+		{
+			name:          "ForAwsChina",
+			isAwsChina:    true,
+			expectedQuota: 5000,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defaultCheck := &defaultQuotasUsageCheckMock{
+				namedQuotasUsageCheckMock: namedQuotasUsageCheckMock{
+					UsageCheckMock: UsageCheckMock{definitions: []QuotaDefinition{{Name: "default_check"}}},
+				},
+			}
+			serviceQuotas := ServiceQuotas{
+				quotasService:    mockClient,
+				isAwsChina:       tc.isAwsChina,
+				otherUsageChecks: []UsageCheck{defaultCheck},
+			}
+			results, err := serviceQuotas.CheckResults()
+
+			assert.NoError(t, err)
+			assert.Len(t, results, 1)
+			assert.Len(t, serviceQuotas.Definitions(), 1)
+			assert.Equal(t, tc.expectedQuota, defaultCheck.quota("key pairs per region", 5000))
+		})
+	}
+}
+
+func TestDefinitions(t *testing.T) {
+	serviceQuotasCheck := &UsageCheckMock{
+		definitions: []QuotaDefinition{{Name: "some_quota", Description: "some quota"}},
+	}
+	otherCheck := &UsageCheckMock{
+		definitions: []QuotaDefinition{
+			{Name: "other_check", Description: "other check"},
+			{Name: "another_check", Description: "another check"},
+		},
+	}
+
+	testCases := []struct {
+		name                string
+		isAwsChina          bool
+		expectedDefinitions []QuotaDefinition
+	}{
+		{
+			name: "WithServiceQuotas",
+			expectedDefinitions: []QuotaDefinition{
+				{Name: "another_check", Description: "another check"},
+				{Name: "other_check", Description: "other check"},
+				{Name: "some_quota", Description: "some quota"},
+			},
+		},
+		{
+			name:       "ForAwsChina",
+			isAwsChina: true,
+			expectedDefinitions: []QuotaDefinition{
+				{Name: "another_check", Description: "another check"},
+				{Name: "other_check", Description: "other check"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			serviceQuotas := ServiceQuotas{
+				isAwsChina:               tc.isAwsChina,
+				serviceQuotasUsageChecks: map[string]UsageCheck{"L-1234": serviceQuotasCheck},
+				otherUsageChecks:         []UsageCheck{otherCheck},
+			}
+
+			assert.Equal(t, tc.expectedDefinitions, serviceQuotas.Definitions())
+		})
+	}
+}
+
+func TestFilterUsageChecks(t *testing.T) {
+	rulesCheck := &UsageCheckMock{
+		definitions: []QuotaDefinition{{Name: "inbound_rules"}, {Name: "outbound_rules"}},
+	}
+	groupsCheck := &UsageCheckMock{definitions: []QuotaDefinition{{Name: "groups"}}}
+	subnetsCheck := &UsageCheckMock{definitions: []QuotaDefinition{{Name: "subnets"}}}
+	asgCheck := &UsageCheckMock{definitions: []QuotaDefinition{{Name: "asgs"}}}
+
+	serviceQuotasChecks := map[string]UsageCheck{
+		"L-1234": rulesCheck,
+		"L-5678": groupsCheck,
+	}
+	otherChecks := []UsageCheck{subnetsCheck, asgCheck}
+
+	filteredServiceQuotasChecks, filteredOtherChecks := filterUsageChecks(serviceQuotasChecks, otherChecks, []string{"outbound_rules", "asgs"})
+
+	assert.Equal(t, map[string]UsageCheck{"L-1234": rulesCheck}, filteredServiceQuotasChecks)
+	assert.Equal(t, []UsageCheck{asgCheck}, filteredOtherChecks)
+}
+
+func TestRoleARN(t *testing.T) {
+	testCases := []struct {
+		name        string
+		region      string
+		expectedARN string
+	}{
+		{
+			name:        "ForAwsPartition",
+			region:      "eu-west-1",
+			expectedARN: "arn:aws:iam::123456789012:role/quotas",
+		},
+		{
+			name:        "ForAwsChinaPartition",
+			region:      "cn-north-1",
+			expectedARN: "arn:aws-cn:iam::123456789012:role/quotas",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			arn, err := RoleARN(tc.region, "123456789012", "quotas")
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedARN, arn)
+		})
+	}
+}
+
+func TestRoleARNWithInvalidRegion(t *testing.T) {
+	arn, err := RoleARN("asdasd", "123456789012", "quotas")
+
+	assert.True(t, errors.Is(err, ErrInvalidRegion))
+	assert.Equal(t, "", arn)
+}
+
 func TestQuotaUsageIdentifier(t *testing.T) {
 	testCases := []struct {
 		name               string
@@ -0,0 +1,290 @@
+package servicequotas
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	logging "github.com/sirupsen/logrus"
+)
+
+const (
+	openSpotRequestState = "open"
+	instantFleetType     = "instant"
+
+	// Spot instance requests made by fleets are tagged with the ID
+	// of their fleet. Their capacity is counted as part of the
+	// fleet's unfulfilled capacity
+	spotFleetRequestIDTag = "aws:ec2spot:fleet-request-id"
+	ec2FleetIDTag         = "aws:ec2:fleet-id"
+
+	vCPUCapacityUnitType      = "vcpu"
+	memoryMiBCapacityUnitType = "memory-mib"
+)
+
+// activeFleetStates are the states of fleets that are still trying
+// to fulfil their target capacity
+var activeFleetStates = map[string]bool{
+	"submitted": true,
+	"active":    true,
+	"modifying": true,
+}
+
+// fleetInstanceType is an instance type a fleet can launch together
+// with the capacity units an instance of it counts as
+type fleetInstanceType struct {
+	instanceType string
+	weight       *float64
+}
+
+// pendingSpotCache caches the vCPUs of Spot capacity that has been
+// requested but not fulfilled yet per instance type, which counts
+// against the Spot vCPU quotas in the same way as running instances
+// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/using-spot-limits.html
+type pendingSpotCache struct {
+	client        ec2iface.EC2API
+	instanceTypes *instanceTypeCatalog
+	ttl           time.Duration
+	lock          sync.Mutex
+	vCPUs         map[string]float64
+	lastRefresh   time.Time
+}
+
+func newPendingSpotCache(client ec2iface.EC2API, instanceTypes *instanceTypeCatalog) *pendingSpotCache {
+	return &pendingSpotCache{client: client, instanceTypes: instanceTypes, ttl: instancesCacheTTL}
+}
+
+// VCPUs returns the vCPUs of open Spot instance requests and of the
+// unfulfilled Spot target capacity of Spot Fleets and EC2 Fleets per
+// instance type, describing them if the cached vCPUs are older
+// than the cache's TTL, or an error
+func (c *pendingSpotCache) VCPUs() (map[string]float64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.vCPUs != nil && time.Since(c.lastRefresh) < c.ttl {
+		return c.vCPUs, nil
+	}
+
+	vCPUs := map[string]float64{}
+	if err := c.addOpenSpotRequests(vCPUs); err != nil {
+		return nil, err
+	}
+	if err := c.addSpotFleets(vCPUs); err != nil {
+		return nil, err
+	}
+	if err := c.addEC2Fleets(vCPUs); err != nil {
+		return nil, err
+	}
+
+	c.vCPUs = vCPUs
+	c.lastRefresh = time.Now()
+	return vCPUs, nil
+}
+
+// addOpenSpotRequests adds the vCPUs of open Spot instance requests
+// that were not made by a fleet. Fulfilled requests are active and
+// their instances are already counted
+func (c *pendingSpotCache) addOpenSpotRequests(vCPUs map[string]float64) error {
+	requests := []*ec2.SpotInstanceRequest{}
+
+	params := &ec2.DescribeSpotInstanceRequestsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("state"),
+				Values: []*string{aws.String(openSpotRequestState)},
+			},
+		},
+	}
+	err := c.client.DescribeSpotInstanceRequestsPages(params,
+		func(page *ec2.DescribeSpotInstanceRequestsOutput, lastPage bool) bool {
+			if page != nil {
+				requests = append(requests, page.SpotInstanceRequests...)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, request := range requests {
+		if aws.StringValue(request.State) != openSpotRequestState || request.InstanceId != nil || isFleetSpotRequest(request) {
+			continue
+		}
+		if request.LaunchSpecification == nil || request.LaunchSpecification.InstanceType == nil {
+			continue
+		}
+
+		instanceType := *request.LaunchSpecification.InstanceType
+		info, known, err := c.instanceTypes.InstanceType(instanceType)
+		if err != nil {
+			return err
+		}
+		if known {
+			vCPUs[instanceType] += float64(info.vCPUs)
+		}
+	}
+
+	return nil
+}
+
+func isFleetSpotRequest(request *ec2.SpotInstanceRequest) bool {
+	for _, tag := range request.Tags {
+		key := aws.StringValue(tag.Key)
+		if key == spotFleetRequestIDTag || key == ec2FleetIDTag {
+			return true
+		}
+	}
+	return false
+}
+
+// addSpotFleets adds the vCPUs of the unfulfilled Spot target
+// capacity of active Spot Fleets
+func (c *pendingSpotCache) addSpotFleets(vCPUs map[string]float64) error {
+	fleets := []*ec2.SpotFleetRequestConfig{}
+
+	params := &ec2.DescribeSpotFleetRequestsInput{}
+	err := c.client.DescribeSpotFleetRequestsPages(params,
+		func(page *ec2.DescribeSpotFleetRequestsOutput, lastPage bool) bool {
+			if page != nil {
+				fleets = append(fleets, page.SpotFleetRequestConfigs...)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, fleet := range fleets {
+		config := fleet.SpotFleetRequestConfig
+		if !activeFleetStates[aws.StringValue(fleet.SpotFleetRequestState)] || config == nil {
+			continue
+		}
+
+		target := float64(aws.Int64Value(config.TargetCapacity) - aws.Int64Value(config.OnDemandTargetCapacity))
+		fulfilled := aws.Float64Value(config.FulfilledCapacity) - aws.Float64Value(config.OnDemandFulfilledCapacity)
+
+		instanceTypes := []fleetInstanceType{}
+		for _, specification := range config.LaunchSpecifications {
+			if specification.InstanceType != nil {
+				instanceTypes = append(instanceTypes, fleetInstanceType{*specification.InstanceType, specification.WeightedCapacity})
+			}
+		}
+		for _, launchTemplateConfig := range config.LaunchTemplateConfigs {
+			for _, override := range launchTemplateConfig.Overrides {
+				if override.InstanceType != nil {
+					instanceTypes = append(instanceTypes, fleetInstanceType{*override.InstanceType, override.WeightedCapacity})
+				}
+			}
+		}
+
+		err := c.addFleetCapacity(vCPUs, aws.StringValue(fleet.SpotFleetRequestId), target-fulfilled, aws.StringValue(config.TargetCapacityUnitType), instanceTypes)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addEC2Fleets adds the vCPUs of the unfulfilled Spot target capacity
+// of active EC2 Fleets. Instant fleets are fulfilled synchronously and
+// have no unfulfilled capacity
+func (c *pendingSpotCache) addEC2Fleets(vCPUs map[string]float64) error {
+	fleets := []*ec2.FleetData{}
+
+	params := &ec2.DescribeFleetsInput{}
+	err := c.client.DescribeFleetsPages(params,
+		func(page *ec2.DescribeFleetsOutput, lastPage bool) bool {
+			if page != nil {
+				fleets = append(fleets, page.Fleets...)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, fleet := range fleets {
+		specification := fleet.TargetCapacitySpecification
+		if !activeFleetStates[aws.StringValue(fleet.FleetState)] || aws.StringValue(fleet.Type) == instantFleetType || specification == nil {
+			continue
+		}
+
+		target := float64(aws.Int64Value(specification.TotalTargetCapacity) - aws.Int64Value(specification.OnDemandTargetCapacity))
+		fulfilled := aws.Float64Value(fleet.FulfilledCapacity) - aws.Float64Value(fleet.FulfilledOnDemandCapacity)
+
+		instanceTypes := []fleetInstanceType{}
+		for _, launchTemplateConfig := range fleet.LaunchTemplateConfigs {
+			for _, override := range launchTemplateConfig.Overrides {
+				if override.InstanceType != nil {
+					instanceTypes = append(instanceTypes, fleetInstanceType{*override.InstanceType, override.WeightedCapacity})
+				}
+			}
+		}
+
+		err := c.addFleetCapacity(vCPUs, aws.StringValue(fleet.FleetId), target-fulfilled, aws.StringValue(specification.TargetCapacityUnitType), instanceTypes)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addFleetCapacity adds the vCPUs of `units` of unfulfilled capacity
+// of a fleet that can launch `instanceTypes`. As the instance types
+// the capacity will be fulfilled with are unknown, it is estimated
+// with the instance type using the fewest vCPUs per capacity unit
+func (c *pendingSpotCache) addFleetCapacity(vCPUs map[string]float64, fleetID string, units float64, unitType string, instanceTypes []fleetInstanceType) error {
+	if units <= 0 {
+		return nil
+	}
+
+	var estimateInstanceType string
+	var estimateVCPUsPerUnit float64
+	for _, instanceType := range instanceTypes {
+		info, known, err := c.instanceTypes.InstanceType(instanceType.instanceType)
+		if err != nil {
+			return err
+		}
+		if !known || info.vCPUs == 0 {
+			continue
+		}
+
+		weight := aws.Float64Value(instanceType.weight)
+		if instanceType.weight == nil {
+			switch unitType {
+			case vCPUCapacityUnitType:
+				weight = float64(info.vCPUs)
+			case memoryMiBCapacityUnitType:
+				continue
+			default:
+				weight = 1
+			}
+		}
+		if weight <= 0 {
+			continue
+		}
+
+		vCPUsPerUnit := float64(info.vCPUs) / weight
+		if estimateInstanceType == "" || vCPUsPerUnit < estimateVCPUsPerUnit {
+			estimateInstanceType = instanceType.instanceType
+			estimateVCPUsPerUnit = vCPUsPerUnit
+		}
+	}
+
+	if estimateInstanceType == "" {
+		logging.Debugf("Can not estimate the vCPUs of the unfulfilled capacity of fleet (%s)", fleetID)
+		return nil
+	}
+
+	vCPUs[estimateInstanceType] += math.Ceil(units * estimateVCPUsPerUnit)
+	return nil
+}
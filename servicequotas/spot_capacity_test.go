@@ -0,0 +1,188 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func (m *mockEC2Client) DescribeSpotInstanceRequestsPages(input *ec2.DescribeSpotInstanceRequestsInput, fn func(*ec2.DescribeSpotInstanceRequestsOutput, bool) bool) error {
+	fn(m.DescribeSpotInstanceRequestsResponse, true)
+	return m.err
+}
+
+func (m *mockEC2Client) DescribeSpotFleetRequestsPages(input *ec2.DescribeSpotFleetRequestsInput, fn func(*ec2.DescribeSpotFleetRequestsOutput, bool) bool) error {
+	fn(m.DescribeSpotFleetRequestsResponse, true)
+	return m.err
+}
+
+func (m *mockEC2Client) DescribeFleetsPages(input *ec2.DescribeFleetsInput, fn func(*ec2.DescribeFleetsOutput, bool) bool) error {
+	fn(m.DescribeFleetsResponse, true)
+	return m.err
+}
+
+func testInstanceTypes() *ec2.DescribeInstanceTypesOutput {
+	return &ec2.DescribeInstanceTypesOutput{
+		InstanceTypes: []*ec2.InstanceTypeInfo{
+			{InstanceType: aws.String("m5.large"), VCpuInfo: &ec2.VCpuInfo{DefaultVCpus: aws.Int64(2)}},
+			{InstanceType: aws.String("m5.xlarge"), VCpuInfo: &ec2.VCpuInfo{DefaultVCpus: aws.Int64(4)}},
+			{InstanceType: aws.String("c5.4xlarge"), VCpuInfo: &ec2.VCpuInfo{DefaultVCpus: aws.Int64(16)}},
+			{InstanceType: aws.String("g5.xlarge"), VCpuInfo: &ec2.VCpuInfo{DefaultVCpus: aws.Int64(4)}},
+		},
+	}
+}
+
+func TestPendingSpotVCPUsWithError(t *testing.T) {
+	mockClient := &mockEC2Client{err: errors.New("some err")}
+
+	vCPUs, err := newPendingSpotCache(mockClient, newInstanceTypeCatalog(mockClient)).VCPUs()
+
+	assert.Error(t, err)
+	assert.Nil(t, vCPUs)
+}
+
+func TestPendingSpotVCPUs(t *testing.T) {
+	testCases := []struct {
+		name          string
+		requests      []*ec2.SpotInstanceRequest
+		spotFleets    []*ec2.SpotFleetRequestConfig
+		ec2Fleets     []*ec2.FleetData
+		expectedVCPUs map[string]float64
+	}{
+		{
+			name:          "WithNothingPending",
+			expectedVCPUs: map[string]float64{},
+		},
+		{
+			name: "WithOpenSpotRequests",
+			requests: []*ec2.SpotInstanceRequest{
+				{
+					State:               aws.String("open"),
+					LaunchSpecification: &ec2.LaunchSpecification{InstanceType: aws.String("m5.xlarge")},
+				},
+				{
+					State:               aws.String("open"),
+					LaunchSpecification: &ec2.LaunchSpecification{InstanceType: aws.String("g5.xlarge")},
+				},
+				{
+					State:               aws.String("open"),
+					LaunchSpecification: &ec2.LaunchSpecification{InstanceType: aws.String("m5.large")},
+					Tags:                []*ec2.Tag{{Key: aws.String("aws:ec2spot:fleet-request-id"), Value: aws.String("sfr-1")}},
+				},
+				{
+					State:               aws.String("active"),
+					InstanceId:          aws.String("i-1"),
+					LaunchSpecification: &ec2.LaunchSpecification{InstanceType: aws.String("m5.large")},
+				},
+			},
+			expectedVCPUs: map[string]float64{
+				"m5.xlarge": 4,
+				"g5.xlarge": 4,
+			},
+		},
+		{
+			name: "WithSpotFleets",
+			spotFleets: []*ec2.SpotFleetRequestConfig{
+				{
+					SpotFleetRequestId:    aws.String("sfr-1"),
+					SpotFleetRequestState: aws.String("active"),
+					SpotFleetRequestConfig: &ec2.SpotFleetRequestConfigData{
+						TargetCapacity:            aws.Int64(10),
+						OnDemandTargetCapacity:    aws.Int64(2),
+						FulfilledCapacity:         aws.Float64(7),
+						OnDemandFulfilledCapacity: aws.Float64(2),
+						LaunchSpecifications: []*ec2.SpotFleetLaunchSpecification{
+							{InstanceType: aws.String("m5.xlarge")},
+							{InstanceType: aws.String("c5.4xlarge"), WeightedCapacity: aws.Float64(8)},
+						},
+					},
+				},
+				{
+					SpotFleetRequestId:    aws.String("sfr-2"),
+					SpotFleetRequestState: aws.String("cancelled_running"),
+					SpotFleetRequestConfig: &ec2.SpotFleetRequestConfigData{
+						TargetCapacity: aws.Int64(10),
+						LaunchSpecifications: []*ec2.SpotFleetLaunchSpecification{
+							{InstanceType: aws.String("m5.xlarge")},
+						},
+					},
+				},
+			},
+			expectedVCPUs: map[string]float64{
+				"c5.4xlarge": 6,
+			},
+		},
+		{
+			name: "WithEC2Fleets",
+			ec2Fleets: []*ec2.FleetData{
+				{
+					FleetId:    aws.String("fleet-1"),
+					FleetState: aws.String("active"),
+					Type:       aws.String("maintain"),
+					TargetCapacitySpecification: &ec2.TargetCapacitySpecification{
+						TotalTargetCapacity:    aws.Int64(32),
+						TargetCapacityUnitType: aws.String("vcpu"),
+					},
+					FulfilledCapacity: aws.Float64(16),
+					LaunchTemplateConfigs: []*ec2.FleetLaunchTemplateConfig{
+						{
+							Overrides: []*ec2.FleetLaunchTemplateOverrides{
+								{InstanceType: aws.String("g5.xlarge")},
+							},
+						},
+					},
+				},
+				{
+					FleetId:    aws.String("fleet-2"),
+					FleetState: aws.String("active"),
+					Type:       aws.String("instant"),
+					TargetCapacitySpecification: &ec2.TargetCapacitySpecification{
+						TotalTargetCapacity: aws.Int64(10),
+					},
+					LaunchTemplateConfigs: []*ec2.FleetLaunchTemplateConfig{
+						{
+							Overrides: []*ec2.FleetLaunchTemplateOverrides{
+								{InstanceType: aws.String("m5.large")},
+							},
+						},
+					},
+				},
+				{
+					FleetId:    aws.String("fleet-3"),
+					FleetState: aws.String("active"),
+					Type:       aws.String("request"),
+					TargetCapacitySpecification: &ec2.TargetCapacitySpecification{
+						TotalTargetCapacity: aws.Int64(10),
+					},
+					LaunchTemplateConfigs: []*ec2.FleetLaunchTemplateConfig{
+						{
+							LaunchTemplateSpecification: &ec2.FleetLaunchTemplateSpecification{LaunchTemplateId: aws.String("lt-1")},
+						},
+					},
+				},
+			},
+			expectedVCPUs: map[string]float64{
+				"g5.xlarge": 16,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := &mockEC2Client{
+				DescribeInstanceTypesResponse:        testInstanceTypes(),
+				DescribeSpotInstanceRequestsResponse: &ec2.DescribeSpotInstanceRequestsOutput{SpotInstanceRequests: tc.requests},
+				DescribeSpotFleetRequestsResponse:    &ec2.DescribeSpotFleetRequestsOutput{SpotFleetRequestConfigs: tc.spotFleets},
+				DescribeFleetsResponse:               &ec2.DescribeFleetsOutput{Fleets: tc.ec2Fleets},
+			}
+
+			vCPUs, err := newPendingSpotCache(mockClient, newInstanceTypeCatalog(mockClient)).VCPUs()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedVCPUs, vCPUs)
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package servicequotas
+
+import (
+	"fmt"
+	"math"
+	"net"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const (
+	availableIPsPerSubnetName = "available_ips_per_subnet"
+	availableIPsPerSubnetDesc = "available IPs per subnet"
+
+	availableIPv6IPsPerSubnetName = "available_ipv6_ips_per_subnet"
+	availableIPv6IPsPerSubnetDesc = "available IPv6 IPs per subnet"
+
+	availablePrefixesPerSubnetName = "available_ipv4_prefixes_per_subnet"
+	availablePrefixesPerSubnetDesc = "available /28 IPv4 prefixes per subnet"
+)
+
+const (
+	// AWS reserves the first four and the last IP address of every
+	// subnet CIDR block
+	// https://docs.aws.amazon.com/vpc/latest/userguide/subnet-sizing.html
+	reservedIPsPerSubnet = 5
+	// ipv4PrefixSize is the number of addresses in a /28 prefix
+	// assigned to network interfaces with prefix delegation
+	ipv4PrefixSize = 16
+	// ipv6PrefixSize is the number of addresses in a /80 prefix
+	// assigned to network interfaces with prefix delegation
+	ipv6PrefixSize = 1 << 48
+
+	associatedCidrBlockState = "associated"
+)
+
+// cidrBlockSize returns the number of addresses in `cidrBlock` or an
+// error if it is not a valid CIDR block
+func cidrBlockSize(cidrBlock string) (float64, error) {
+	_, ipNet, err := net.ParseCIDR(cidrBlock)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrFailedToConvertCidr, err)
+	}
+
+	ones, bits := ipNet.Mask.Size()
+	return math.Pow(2, float64(bits-ones)), nil
+}
+
+// subnetCapacity returns the number of addresses in `cidrBlock` that
+// can be assigned, which excludes the addresses reserved by AWS
+func subnetCapacity(cidrBlock string) (float64, error) {
+	size, err := cidrBlockSize(cidrBlock)
+	if err != nil {
+		return 0, err
+	}
+	return math.Max(size-reservedIPsPerSubnet, 0), nil
+}
+
+// subnetPrefixCapacity returns the number of /28 prefixes in
+// `cidrBlock` that can be assigned. The first and last prefixes
+// contain addresses reserved by AWS and can not be assigned
+func subnetPrefixCapacity(cidrBlock string) (float64, error) {
+	size, err := cidrBlockSize(cidrBlock)
+	if err != nil {
+		return 0, err
+	}
+	return math.Max(math.Floor(size/ipv4PrefixSize)-2, 0), nil
+}
+
+// AvailableIpsPerSubnetUsageCheck implements the UsageCheckInterface
+// for available IPs per subnet
+type AvailableIpsPerSubnetUsageCheck struct {
+	vpcs              *vpcsCache
+	networkInterfaces *networkInterfacesCache
+	// prefixDelegation additionally reports the usage of every
+	// subnet in /28 prefixes
+	prefixDelegation bool
+}
+
+// Usage returns the usage for each subnet ID with the usage value
+// being the number of used IPv4 addresses in that subnet and the
+// quota the number of addresses that can be assigned or an error.
+// Subnets with IPv6 CIDR blocks additionally report their IPv6
+// address usage and, with prefix delegation enabled, all subnets with
+// an IPv4 CIDR block report their estimated /28 prefix usage.
+// IPv6-only subnets only report their IPv6 address usage
+func (c *AvailableIpsPerSubnetUsageCheck) Usage() ([]QuotaUsage, error) {
+	subnets, err := c.vpcs.Subnets()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	availabilityInfos := []QuotaUsage{}
+	ipv6Subnets := []*ec2.Subnet{}
+	for _, subnet := range subnets {
+		if len(associatedIPv6CidrBlocks(subnet)) > 0 {
+			ipv6Subnets = append(ipv6Subnets, subnet)
+		}
+
+		// IPv6-only subnets have no IPv4 CIDR block to report on
+		if subnet.CidrBlock == nil {
+			continue
+		}
+
+		maxNumOfIPs, err := subnetCapacity(*subnet.CidrBlock)
+		if err != nil {
+			return nil, err
+		}
+
+		tags := ec2TagsToQuotaUsageTags(subnet.Tags)
+		availableIPs := float64(*subnet.AvailableIpAddressCount)
+		availabilityInfo := QuotaUsage{
+			Name:         availableIPsPerSubnetName,
+			ResourceName: subnet.SubnetId,
+			Description:  availableIPsPerSubnetDesc,
+			Usage:        maxNumOfIPs - availableIPs,
+			Quota:        maxNumOfIPs,
+			Tags:         tags,
+		}
+		availabilityInfos = append(availabilityInfos, availabilityInfo)
+
+		if c.prefixDelegation {
+			maxNumOfPrefixes, err := subnetPrefixCapacity(*subnet.CidrBlock)
+			if err != nil {
+				return nil, err
+			}
+
+			// This is an estimate as the available addresses may be
+			// fragmented across prefixes
+			availablePrefixes := math.Min(math.Floor(availableIPs/ipv4PrefixSize), maxNumOfPrefixes)
+			prefixInfo := QuotaUsage{
+				Name:         availablePrefixesPerSubnetName,
+				ResourceName: subnet.SubnetId,
+				Description:  availablePrefixesPerSubnetDesc,
+				Usage:        maxNumOfPrefixes - availablePrefixes,
+				Quota:        maxNumOfPrefixes,
+				Tags:         tags,
+			}
+			availabilityInfos = append(availabilityInfos, prefixInfo)
+		}
+	}
+
+	if len(ipv6Subnets) == 0 {
+		return availabilityInfos, nil
+	}
+
+	usedIPv6IPs, err := ipv6AddressesPerSubnet(c.networkInterfaces)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	for _, subnet := range ipv6Subnets {
+		var maxNumOfIPs float64
+		for _, cidrBlock := range associatedIPv6CidrBlocks(subnet) {
+			capacity, err := subnetCapacity(cidrBlock)
+			if err != nil {
+				return nil, err
+			}
+			maxNumOfIPs += capacity
+		}
+
+		availabilityInfo := QuotaUsage{
+			Name:         availableIPv6IPsPerSubnetName,
+			ResourceName: subnet.SubnetId,
+			Description:  availableIPv6IPsPerSubnetDesc,
+			Usage:        usedIPv6IPs[*subnet.SubnetId],
+			Quota:        maxNumOfIPs,
+			Tags:         ec2TagsToQuotaUsageTags(subnet.Tags),
+		}
+		availabilityInfos = append(availabilityInfos, availabilityInfo)
+	}
+
+	return availabilityInfos, nil
+}
+
+// Definitions returns the available IPs per subnet information
+func (c *AvailableIpsPerSubnetUsageCheck) Definitions() []QuotaDefinition {
+	definitions := []QuotaDefinition{
+		{Name: availableIPsPerSubnetName, Description: availableIPsPerSubnetDesc},
+		{Name: availableIPv6IPsPerSubnetName, Description: availableIPv6IPsPerSubnetDesc},
+	}
+
+	if c.prefixDelegation {
+		definitions = append(definitions, QuotaDefinition{Name: availablePrefixesPerSubnetName, Description: availablePrefixesPerSubnetDesc})
+	}
+	return definitions
+}
+
+func associatedIPv6CidrBlocks(subnet *ec2.Subnet) []string {
+	cidrBlocks := []string{}
+	for _, association := range subnet.Ipv6CidrBlockAssociationSet {
+		state := association.Ipv6CidrBlockState
+		if association.Ipv6CidrBlock != nil && state != nil && state.State != nil && *state.State == associatedCidrBlockState {
+			cidrBlocks = append(cidrBlocks, *association.Ipv6CidrBlock)
+		}
+	}
+	return cidrBlocks
+}
+
+// ipv6AddressesPerSubnet returns the number of IPv6 addresses
+// assigned to network interfaces, including delegated prefixes, by
+// subnet ID
+func ipv6AddressesPerSubnet(networkInterfaces *networkInterfacesCache) (map[string]float64, error) {
+	enis, err := networkInterfaces.NetworkInterfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := map[string]float64{}
+	for _, eni := range enis {
+		if eni.SubnetId == nil {
+			continue
+		}
+		addresses[*eni.SubnetId] += float64(len(eni.Ipv6Addresses))
+		addresses[*eni.SubnetId] += float64(len(eni.Ipv6Prefixes)) * ipv6PrefixSize
+	}
+
+	return addresses, nil
+}
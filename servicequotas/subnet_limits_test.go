@@ -0,0 +1,346 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func (m *mockEC2Client) DescribeSubnetsPages(input *ec2.DescribeSubnetsInput, fn func(*ec2.DescribeSubnetsOutput, bool) bool) error {
+	m.DescribeSubnetsCalls++
+	fn(m.DescribeSubnetsResponse, true)
+	return m.err
+}
+
+func TestSubnetCapacity(t *testing.T) {
+	testCases := []struct {
+		name                     string
+		cidrBlock                string
+		expectedCapacity         float64
+		expectedPrefixesCapacity float64
+	}{
+		{
+			name:                     "WithSmallestSubnet",
+			cidrBlock:                "10.0.0.0/28",
+			expectedCapacity:         11,
+			expectedPrefixesCapacity: 0,
+		},
+		{
+			name:                     "WithLargestSubnet",
+			cidrBlock:                "10.0.0.0/16",
+			expectedCapacity:         65531,
+			expectedPrefixesCapacity: 4094,
+		},
+		{
+			name:                     "WithSingleDigitPrefix",
+			cidrBlock:                "10.0.0.0/9",
+			expectedCapacity:         8388603,
+			expectedPrefixesCapacity: 524286,
+		},
+		{
+			name:                     "WithIPv6",
+			cidrBlock:                "2001:db8:1234:1a00::/64",
+			expectedCapacity:         18446744073709551611,
+			expectedPrefixesCapacity: 1152921504606846974,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			capacity, err := subnetCapacity(tc.cidrBlock)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCapacity, capacity)
+
+			prefixCapacity, err := subnetPrefixCapacity(tc.cidrBlock)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPrefixesCapacity, prefixCapacity)
+		})
+	}
+}
+
+func TestAvailableIpsPerSubnetUsageWithError(t *testing.T) {
+	mockClient := &mockEC2Client{
+		err:                     errors.New("some err"),
+		DescribeSubnetsResponse: nil,
+	}
+
+	check := AvailableIpsPerSubnetUsageCheck{vpcs: newVPCsCache(mockClient, testAccountID()), networkInterfaces: newNetworkInterfacesCache(mockClient)}
+	usage, err := check.Usage()
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+	assert.Nil(t, usage)
+}
+
+func TestAvailableIpsPerSubnetUsageWithInvalidCidrConversion(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeSubnetsResponse: &ec2.DescribeSubnetsOutput{
+			Subnets: []*ec2.Subnet{
+				{
+					AvailabilityZone:        aws.String("eu-west-1"),
+					AvailableIpAddressCount: aws.Int64(4096),
+					CidrBlock:               aws.String("invalid-cidr"),
+					SubnetId:                aws.String("subnet-id"),
+				},
+			},
+		},
+	}
+	check := AvailableIpsPerSubnetUsageCheck{vpcs: newVPCsCache(mockClient, testAccountID()), networkInterfaces: newNetworkInterfacesCache(mockClient)}
+	usage, err := check.Usage()
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrFailedToConvertCidr))
+	assert.Nil(t, usage)
+}
+
+func TestAvailableIpsPerSubnetUsage(t *testing.T) {
+	testCases := []struct {
+		name          string
+		subnets       []*ec2.Subnet
+		expectedUsage []QuotaUsage
+	}{
+		{
+			name:          "WithNoSubnets",
+			subnets:       []*ec2.Subnet{},
+			expectedUsage: []QuotaUsage{},
+		},
+		{
+			name: "WithSingleSubnet",
+			subnets: []*ec2.Subnet{
+				{
+					AvailabilityZone:        aws.String("eu-west-1"),
+					AvailableIpAddressCount: aws.Int64(4091),
+					CidrBlock:               aws.String("100.10.10.0/20"),
+					SubnetId:                aws.String("subnet-id"),
+				},
+			},
+			expectedUsage: []QuotaUsage{
+				{
+					Name:         availableIPsPerSubnetName,
+					ResourceName: aws.String("subnet-id"),
+					Description:  availableIPsPerSubnetDesc,
+					Usage:        float64(0),
+					Quota:        float64(4091),
+				},
+			},
+		},
+		{
+			name: "WithMultipleSubnets",
+			subnets: []*ec2.Subnet{
+				{
+					AvailabilityZone:        aws.String("eu-west-1"),
+					AvailableIpAddressCount: aws.Int64(4091),
+					CidrBlock:               aws.String("100.10.10.0/20"),
+					SubnetId:                aws.String("subnet-id-1"),
+				},
+				{
+					AvailabilityZone:        aws.String("eu-west-1"),
+					AvailableIpAddressCount: aws.Int64(0),
+					CidrBlock:               aws.String("100.10.10.0/21"),
+					SubnetId:                aws.String("subnet-id-2"),
+				},
+				{
+					AvailabilityZone:        aws.String("eu-west-1"),
+					AvailableIpAddressCount: aws.Int64(100),
+					CidrBlock:               aws.String("100.10.10.0/21"),
+					SubnetId:                aws.String("subnet-id-2"),
+				},
+				{
+					AvailabilityZone:        aws.String("eu-west-1"),
+					AvailableIpAddressCount: aws.Int64(1019),
+					CidrBlock:               aws.String("100.10.10.0/22"),
+					SubnetId:                aws.String("subnet-id-3"),
+				},
+			},
+			expectedUsage: []QuotaUsage{
+				{
+					Name:         availableIPsPerSubnetName,
+					ResourceName: aws.String("subnet-id-1"),
+					Description:  availableIPsPerSubnetDesc,
+					Usage:        float64(0),
+					Quota:        float64(4091),
+				},
+				{
+					Name:         availableIPsPerSubnetName,
+					ResourceName: aws.String("subnet-id-2"),
+					Description:  availableIPsPerSubnetDesc,
+					Usage:        float64(2043),
+					Quota:        float64(2043),
+				},
+				{
+					Name:         availableIPsPerSubnetName,
+					ResourceName: aws.String("subnet-id-2"),
+					Description:  availableIPsPerSubnetDesc,
+					Usage:        float64(1943),
+					Quota:        float64(2043),
+				},
+				{
+					Name:         availableIPsPerSubnetName,
+					ResourceName: aws.String("subnet-id-3"),
+					Description:  availableIPsPerSubnetDesc,
+					Usage:        float64(0),
+					Quota:        float64(1019),
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := &mockEC2Client{
+				err: nil,
+				DescribeSubnetsResponse: &ec2.DescribeSubnetsOutput{
+					Subnets: tc.subnets,
+				},
+			}
+
+			check := AvailableIpsPerSubnetUsageCheck{vpcs: newVPCsCache(mockClient, testAccountID()), networkInterfaces: newNetworkInterfacesCache(mockClient)}
+			usage, err := check.Usage()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedUsage, usage)
+		})
+	}
+}
+
+func TestAvailableIpsPerSubnetUsageWithIPv6(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeSubnetsResponse: &ec2.DescribeSubnetsOutput{
+			Subnets: []*ec2.Subnet{
+				{
+					AvailableIpAddressCount: aws.Int64(250),
+					CidrBlock:               aws.String("10.0.1.0/24"),
+					SubnetId:                aws.String("subnet-dualstack"),
+					Ipv6CidrBlockAssociationSet: []*ec2.SubnetIpv6CidrBlockAssociation{
+						{
+							Ipv6CidrBlock:      aws.String("2001:db8:1234:1a00::/64"),
+							Ipv6CidrBlockState: &ec2.SubnetCidrBlockState{State: aws.String("associated")},
+						},
+					},
+				},
+				{
+					AvailableIpAddressCount: aws.Int64(251),
+					CidrBlock:               aws.String("10.0.2.0/24"),
+					SubnetId:                aws.String("subnet-disassociated"),
+					Ipv6CidrBlockAssociationSet: []*ec2.SubnetIpv6CidrBlockAssociation{
+						{
+							Ipv6CidrBlock:      aws.String("2001:db8:1234:1b00::/64"),
+							Ipv6CidrBlockState: &ec2.SubnetCidrBlockState{State: aws.String("disassociated")},
+						},
+					},
+				},
+				{
+					AvailableIpAddressCount: aws.Int64(0),
+					SubnetId:                aws.String("subnet-ipv6only"),
+					Ipv6Native:              aws.Bool(true),
+					Ipv6CidrBlockAssociationSet: []*ec2.SubnetIpv6CidrBlockAssociation{
+						{
+							Ipv6CidrBlock:      aws.String("2001:db8:1234:1c00::/64"),
+							Ipv6CidrBlockState: &ec2.SubnetCidrBlockState{State: aws.String("associated")},
+						},
+					},
+				},
+			},
+		},
+		DescribeNetworkInterfacesResponse: &ec2.DescribeNetworkInterfacesOutput{
+			NetworkInterfaces: []*ec2.NetworkInterface{
+				{
+					SubnetId: aws.String("subnet-dualstack"),
+					Ipv6Addresses: []*ec2.NetworkInterfaceIpv6Address{
+						{Ipv6Address: aws.String("2001:db8:1234:1a00::1")},
+						{Ipv6Address: aws.String("2001:db8:1234:1a00::2")},
+					},
+				},
+				{
+					SubnetId: aws.String("subnet-dualstack"),
+					Ipv6Prefixes: []*ec2.Ipv6PrefixSpecification{
+						{Ipv6Prefix: aws.String("2001:db8:1234:1a00:1::/80")},
+					},
+				},
+				{
+					SubnetId: aws.String("subnet-ipv6only"),
+					Ipv6Addresses: []*ec2.NetworkInterfaceIpv6Address{
+						{Ipv6Address: aws.String("2001:db8:1234:1c00::1")},
+					},
+				},
+			},
+		},
+	}
+
+	check := AvailableIpsPerSubnetUsageCheck{vpcs: newVPCsCache(mockClient, testAccountID()), networkInterfaces: newNetworkInterfacesCache(mockClient)}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         availableIPsPerSubnetName,
+			ResourceName: aws.String("subnet-dualstack"),
+			Description:  availableIPsPerSubnetDesc,
+			Usage:        1,
+			Quota:        251,
+		},
+		{
+			Name:         availableIPsPerSubnetName,
+			ResourceName: aws.String("subnet-disassociated"),
+			Description:  availableIPsPerSubnetDesc,
+			Usage:        0,
+			Quota:        251,
+		},
+		{
+			Name:         availableIPv6IPsPerSubnetName,
+			ResourceName: aws.String("subnet-dualstack"),
+			Description:  availableIPv6IPsPerSubnetDesc,
+			Usage:        2 + ipv6PrefixSize,
+			Quota:        18446744073709551611,
+		},
+		{
+			Name:         availableIPv6IPsPerSubnetName,
+			ResourceName: aws.String("subnet-ipv6only"),
+			Description:  availableIPv6IPsPerSubnetDesc,
+			Usage:        1,
+			Quota:        18446744073709551611,
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
+
+func TestAvailableIpsPerSubnetUsageWithPrefixDelegation(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeSubnetsResponse: &ec2.DescribeSubnetsOutput{
+			Subnets: []*ec2.Subnet{
+				{
+					AvailableIpAddressCount: aws.Int64(100),
+					CidrBlock:               aws.String("10.0.0.0/24"),
+					SubnetId:                aws.String("subnet-eks"),
+				},
+			},
+		},
+	}
+
+	check := AvailableIpsPerSubnetUsageCheck{vpcs: newVPCsCache(mockClient, testAccountID()), networkInterfaces: newNetworkInterfacesCache(mockClient), prefixDelegation: true}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         availableIPsPerSubnetName,
+			ResourceName: aws.String("subnet-eks"),
+			Description:  availableIPsPerSubnetDesc,
+			Usage:        151,
+			Quota:        251,
+		},
+		{
+			Name:         availablePrefixesPerSubnetName,
+			ResourceName: aws.String("subnet-eks"),
+			Description:  availablePrefixesPerSubnetDesc,
+			Usage:        8,
+			Quota:        14,
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+	assert.Len(t, check.Definitions(), 3)
+}
@@ -0,0 +1,387 @@
+package servicequotas
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+const (
+	transitGatewaysPerAccountName = "transit_gateways_per_account"
+	transitGatewaysPerAccountDesc = "transit gateways per account"
+
+	attachmentsPerTransitGatewayName = "attachments_per_transit_gateway"
+	attachmentsPerTransitGatewayDesc = "attachments per transit gateway"
+
+	routeTablesPerTransitGatewayName = "route_tables_per_transit_gateway"
+	routeTablesPerTransitGatewayDesc = "route tables per transit gateway"
+
+	routesPerTransitGatewayName = "routes_per_transit_gateway"
+	routesPerTransitGatewayDesc = "static and propagated routes per transit gateway"
+
+	// routeTablesPerTransitGatewayQuotaName and
+	// routesPerTransitGatewayQuotaName are the names the quotas are
+	// listed under in Service Quotas
+	routeTablesPerTransitGatewayQuotaName = "Route tables per transit gateway"
+	routesPerTransitGatewayQuotaName      = "Routes per transit gateway"
+	// defaultRouteTablesPerTransitGateway and
+	// defaultRoutesPerTransitGateway are the default quotas of route
+	// tables per transit gateway and of routes combined across all
+	// route tables of a transit gateway, used when the quotas are not
+	// listed
+	// https://docs.aws.amazon.com/vpc/latest/tgw/transit-gateway-quotas.html
+	defaultRouteTablesPerTransitGateway = 20
+	defaultRoutesPerTransitGateway      = 10000
+
+	// maxTransitGatewayRoutesPerSearch is the maximum number of routes
+	// a single search of a transit gateway route table returns
+	maxTransitGatewayRoutesPerSearch = 1000
+
+	transitGatewayIDLabel = "transit_gateway_id"
+)
+
+// deletedTransitGatewayStates are the states of transit gateways,
+// attachments and route tables that do not count against the transit
+// gateway quotas
+var deletedTransitGatewayStates = map[string]bool{
+	"deleted":  true,
+	"failed":   true,
+	"rejected": true,
+}
+
+// transitGatewayRouteStates are the states of transit gateway routes
+// that count against the routes per transit gateway quota
+var transitGatewayRouteStates = []string{
+	ec2.TransitGatewayRouteStateActive,
+	ec2.TransitGatewayRouteStateBlackhole,
+}
+
+// TransitGatewaysPerAccountUsageCheck implements the UsageCheck
+// interface for transit gateways per account
+type TransitGatewaysPerAccountUsageCheck struct {
+	client    ec2iface.EC2API
+	accountID *accountIDCache
+}
+
+// Usage returns usage for transit gateways per account as the number
+// of transit gateways owned by the account in the region or an error
+func (c *TransitGatewaysPerAccountUsageCheck) Usage() ([]QuotaUsage, error) {
+	transitGateways, err := describeTransitGateways(c.client, c.accountID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	usage := []QuotaUsage{
+		{
+			Name:        transitGatewaysPerAccountName,
+			Description: transitGatewaysPerAccountDesc,
+			Usage:       float64(len(transitGateways)),
+		},
+	}
+	return usage, nil
+}
+
+// Definitions returns the transit gateways per account quota
+func (c *TransitGatewaysPerAccountUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: transitGatewaysPerAccountName, Description: transitGatewaysPerAccountDesc}}
+}
+
+// AttachmentsPerTransitGatewayUsageCheck implements the UsageCheck
+// interface for attachments per transit gateway
+type AttachmentsPerTransitGatewayUsageCheck struct {
+	client    ec2iface.EC2API
+	accountID *accountIDCache
+}
+
+// Usage returns usage for each transit gateway ID with the usage
+// value being the number of its attachments or an error
+func (c *AttachmentsPerTransitGatewayUsageCheck) Usage() ([]QuotaUsage, error) {
+	transitGateways, err := describeTransitGateways(c.client, c.accountID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	attachmentsPerTransitGateway := map[string]int{}
+
+	params := &ec2.DescribeTransitGatewayAttachmentsInput{}
+	err = c.client.DescribeTransitGatewayAttachmentsPages(params,
+		func(page *ec2.DescribeTransitGatewayAttachmentsOutput, lastPage bool) bool {
+			if page != nil {
+				for _, attachment := range page.TransitGatewayAttachments {
+					if !deletedTransitGatewayStates[aws.StringValue(attachment.State)] {
+						attachmentsPerTransitGateway[aws.StringValue(attachment.TransitGatewayId)]++
+					}
+				}
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	return transitGatewayCountUsages(attachmentsPerTransitGatewayName, attachmentsPerTransitGatewayDesc, transitGateways, attachmentsPerTransitGateway), nil
+}
+
+// Definitions returns the attachments per transit gateway quota
+func (c *AttachmentsPerTransitGatewayUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: attachmentsPerTransitGatewayName, Description: attachmentsPerTransitGatewayDesc}}
+}
+
+// TransitGatewayRouteTablesUsageCheck implements the UsageCheck
+// interface for route tables per transit gateway and routes per
+// transit gateway
+type TransitGatewayRouteTablesUsageCheck struct {
+	listedQuotas
+	client    ec2iface.EC2API
+	accountID *accountIDCache
+}
+
+func (c *TransitGatewayRouteTablesUsageCheck) serviceCode() string {
+	return "ec2"
+}
+
+// Usage returns usage for each transit gateway ID with the usage
+// values being the number of its route tables and the number of
+// static and propagated routes combined across all of its route
+// tables, with the listed quotas or their defaults, or an error
+func (c *TransitGatewayRouteTablesUsageCheck) Usage() ([]QuotaUsage, error) {
+	transitGateways, err := describeTransitGateways(c.client, c.accountID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	routeTables := []*ec2.TransitGatewayRouteTable{}
+
+	params := &ec2.DescribeTransitGatewayRouteTablesInput{}
+	err = c.client.DescribeTransitGatewayRouteTablesPages(params,
+		func(page *ec2.DescribeTransitGatewayRouteTablesOutput, lastPage bool) bool {
+			if page != nil {
+				for _, routeTable := range page.TransitGatewayRouteTables {
+					if !deletedTransitGatewayStates[aws.StringValue(routeTable.State)] {
+						routeTables = append(routeTables, routeTable)
+					}
+				}
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	routeTablesPerTransitGateway := map[string]int{}
+	routesPerTransitGateway := map[string]int{}
+	for _, routeTable := range routeTables {
+		if routeTable.TransitGatewayRouteTableId == nil {
+			continue
+		}
+
+		routes, err := c.routeCount(*routeTable.TransitGatewayRouteTableId)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+		}
+
+		transitGatewayID := aws.StringValue(routeTable.TransitGatewayId)
+		routeTablesPerTransitGateway[transitGatewayID]++
+		routesPerTransitGateway[transitGatewayID] += routes
+	}
+
+	routeTablesQuota := c.quota(routeTablesPerTransitGatewayQuotaName, defaultRouteTablesPerTransitGateway)
+	quotaUsages := transitGatewayCountUsages(routeTablesPerTransitGatewayName, routeTablesPerTransitGatewayDesc, transitGateways, routeTablesPerTransitGateway)
+	for i := range quotaUsages {
+		quotaUsages[i].Quota = routeTablesQuota
+	}
+
+	routesQuota := c.quota(routesPerTransitGatewayQuotaName, defaultRoutesPerTransitGateway)
+	routeUsages := transitGatewayCountUsages(routesPerTransitGatewayName, routesPerTransitGatewayDesc, transitGateways, routesPerTransitGateway)
+	for i := range routeUsages {
+		routeUsages[i].Quota = routesQuota
+	}
+
+	return append(quotaUsages, routeUsages...), nil
+}
+
+// routeCount returns the number of static and propagated routes of
+// the transit gateway route table `routeTableID` or an error. Route
+// searches are not paginated, so route tables with more routes than
+// a search returns are counted by searching the routes to each half
+// of the address space separately, along with the routes to prefix
+// lists
+func (c *TransitGatewayRouteTablesUsageCheck) routeCount(routeTableID string) (int, error) {
+	routes, truncated, err := c.searchRoutes(routeTableID)
+	if err != nil {
+		return 0, err
+	}
+	if !truncated {
+		return routes, nil
+	}
+
+	ipv4Routes, err := c.cidrRouteCount(routeTableID, "0.0.0.0/0")
+	if err != nil {
+		return 0, err
+	}
+
+	ipv6Routes, err := c.cidrRouteCount(routeTableID, "::/0")
+	if err != nil {
+		return 0, err
+	}
+
+	prefixListRoutes := 0
+	params := &ec2.GetTransitGatewayPrefixListReferencesInput{TransitGatewayRouteTableId: aws.String(routeTableID)}
+	err = c.client.GetTransitGatewayPrefixListReferencesPages(params,
+		func(page *ec2.GetTransitGatewayPrefixListReferencesOutput, lastPage bool) bool {
+			if page != nil {
+				prefixListRoutes += len(page.TransitGatewayPrefixListReferences)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return ipv4Routes + ipv6Routes + prefixListRoutes, nil
+}
+
+// cidrRouteCount returns the number of routes of the transit gateway
+// route table `routeTableID` to `cidrBlock` or any of its subnets or
+// an error. If the search is truncated, the route to `cidrBlock` and
+// the routes to each of its halves are counted separately
+func (c *TransitGatewayRouteTablesUsageCheck) cidrRouteCount(routeTableID, cidrBlock string) (int, error) {
+	routes, truncated, err := c.searchRoutes(routeTableID, &ec2.Filter{
+		Name:   aws.String("route-search.subnet-of-match"),
+		Values: []*string{aws.String(cidrBlock)},
+	})
+	if err != nil || !truncated {
+		return routes, err
+	}
+
+	halves, err := splitCidrBlock(cidrBlock)
+	if err != nil {
+		return 0, err
+	}
+
+	routes, _, err = c.searchRoutes(routeTableID, &ec2.Filter{
+		Name:   aws.String("route-search.exact-match"),
+		Values: []*string{aws.String(cidrBlock)},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, half := range halves {
+		halfRoutes, err := c.cidrRouteCount(routeTableID, half)
+		if err != nil {
+			return 0, err
+		}
+		routes += halfRoutes
+	}
+	return routes, nil
+}
+
+// searchRoutes returns the number of active and blackhole routes of
+// the transit gateway route table `routeTableID` matching `filters`
+// and whether the search was truncated or an error
+func (c *TransitGatewayRouteTablesUsageCheck) searchRoutes(routeTableID string, filters ...*ec2.Filter) (int, bool, error) {
+	params := &ec2.SearchTransitGatewayRoutesInput{
+		TransitGatewayRouteTableId: aws.String(routeTableID),
+		Filters: append([]*ec2.Filter{
+			{
+				Name:   aws.String("state"),
+				Values: aws.StringSlice(transitGatewayRouteStates),
+			},
+		}, filters...),
+		MaxResults: aws.Int64(maxTransitGatewayRoutesPerSearch),
+	}
+	output, err := c.client.SearchTransitGatewayRoutes(params)
+	if err != nil {
+		return 0, false, err
+	}
+	return len(output.Routes), aws.BoolValue(output.AdditionalRoutesAvailable), nil
+}
+
+// Definitions returns the route tables per transit gateway and routes
+// per transit gateway quotas
+func (c *TransitGatewayRouteTablesUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{
+		{Name: routeTablesPerTransitGatewayName, Description: routeTablesPerTransitGatewayDesc},
+		{Name: routesPerTransitGatewayName, Description: routesPerTransitGatewayDesc},
+	}
+}
+
+// splitCidrBlock returns the two halves of `cidrBlock` or an error if
+// it is not a valid CIDR block or a single address
+func splitCidrBlock(cidrBlock string) ([]string, error) {
+	_, ipNet, err := net.ParseCIDR(cidrBlock)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToConvertCidr, err)
+	}
+
+	ones, bits := ipNet.Mask.Size()
+	if ones == bits {
+		return nil, fmt.Errorf("%w: %s can not be split", ErrFailedToConvertCidr, cidrBlock)
+	}
+
+	mask := net.CIDRMask(ones+1, bits)
+	upper := make(net.IP, len(ipNet.IP))
+	copy(upper, ipNet.IP)
+	upper[ones/8] |= 0x80 >> (ones % 8)
+
+	lowerNet := net.IPNet{IP: ipNet.IP, Mask: mask}
+	upperNet := net.IPNet{IP: upper, Mask: mask}
+	return []string{lowerNet.String(), upperNet.String()}, nil
+}
+
+// transitGatewayCountUsages returns usage for each transit gateway in
+// `transitGateways` with the usage value being its count in `counts`,
+// labelled with the transit gateway ID and tagged with its tags
+func transitGatewayCountUsages(name, description string, transitGateways []*ec2.TransitGateway, counts map[string]int) []QuotaUsage {
+	quotaUsages := []QuotaUsage{}
+	for _, transitGateway := range transitGateways {
+		if transitGateway.TransitGatewayId == nil {
+			continue
+		}
+
+		quotaUsages = append(quotaUsages, QuotaUsage{
+			Name:         name,
+			ResourceName: transitGateway.TransitGatewayId,
+			Description:  description,
+			Usage:        float64(counts[*transitGateway.TransitGatewayId]),
+			Tags:         ec2TagsToQuotaUsageTags(transitGateway.Tags),
+			Labels:       map[string]string{transitGatewayIDLabel: *transitGateway.TransitGatewayId},
+		})
+	}
+	return quotaUsages
+}
+
+// describeTransitGateways returns the transit gateways owned by the
+// account that have not been deleted. Transit gateways shared from
+// other accounts do not count against the quotas
+func describeTransitGateways(client ec2iface.EC2API, accountIDs *accountIDCache) ([]*ec2.TransitGateway, error) {
+	accountID, err := accountIDs.AccountID()
+	if err != nil {
+		return nil, err
+	}
+
+	transitGateways := []*ec2.TransitGateway{}
+	err = client.DescribeTransitGatewaysPages(&ec2.DescribeTransitGatewaysInput{},
+		func(page *ec2.DescribeTransitGatewaysOutput, lastPage bool) bool {
+			if page != nil {
+				for _, transitGateway := range page.TransitGateways {
+					if aws.StringValue(transitGateway.OwnerId) == accountID && !deletedTransitGatewayStates[aws.StringValue(transitGateway.State)] {
+						transitGateways = append(transitGateways, transitGateway)
+					}
+				}
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return transitGateways, nil
+}
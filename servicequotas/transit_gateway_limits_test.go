@@ -0,0 +1,349 @@
+package servicequotas
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	awsservicequotas "github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/stretchr/testify/assert"
+)
+
+func (m *mockEC2Client) DescribeTransitGatewaysPages(input *ec2.DescribeTransitGatewaysInput, fn func(*ec2.DescribeTransitGatewaysOutput, bool) bool) error {
+	fn(m.DescribeTransitGatewaysResponse, true)
+	return m.err
+}
+
+func (m *mockEC2Client) DescribeTransitGatewayAttachmentsPages(input *ec2.DescribeTransitGatewayAttachmentsInput, fn func(*ec2.DescribeTransitGatewayAttachmentsOutput, bool) bool) error {
+	fn(m.DescribeTransitGatewayAttachmentsResponse, true)
+	return m.err
+}
+
+func (m *mockEC2Client) DescribeTransitGatewayRouteTablesPages(input *ec2.DescribeTransitGatewayRouteTablesInput, fn func(*ec2.DescribeTransitGatewayRouteTablesOutput, bool) bool) error {
+	fn(m.DescribeTransitGatewayRouteTablesResponse, true)
+	return m.err
+}
+
+// SearchTransitGatewayRoutes returns the routes of the route table
+// matching the state, exact and subnet of match filters, truncated to
+// the max results like the EC2 API
+func (m *mockEC2Client) SearchTransitGatewayRoutes(input *ec2.SearchTransitGatewayRoutesInput) (*ec2.SearchTransitGatewayRoutesOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	matches := func(route *ec2.TransitGatewayRoute, filter *ec2.Filter) bool {
+		value := aws.StringValue(filter.Values[0])
+		switch aws.StringValue(filter.Name) {
+		case "state":
+			for _, state := range filter.Values {
+				if aws.StringValue(state) == aws.StringValue(route.State) {
+					return true
+				}
+			}
+			return false
+		case "route-search.exact-match":
+			return aws.StringValue(route.DestinationCidrBlock) == value
+		case "route-search.subnet-of-match":
+			if route.DestinationCidrBlock == nil {
+				return false
+			}
+			_, filterNet, _ := net.ParseCIDR(value)
+			_, routeNet, _ := net.ParseCIDR(*route.DestinationCidrBlock)
+			filterOnes, filterBits := filterNet.Mask.Size()
+			routeOnes, routeBits := routeNet.Mask.Size()
+			return filterBits == routeBits && routeOnes >= filterOnes && filterNet.Contains(routeNet.IP)
+		}
+		return false
+	}
+
+	output := &ec2.SearchTransitGatewayRoutesOutput{AdditionalRoutesAvailable: aws.Bool(false)}
+	for _, route := range m.TransitGatewayRoutes[*input.TransitGatewayRouteTableId] {
+		matchesAll := true
+		for _, filter := range input.Filters {
+			matchesAll = matchesAll && matches(route, filter)
+		}
+		if !matchesAll {
+			continue
+		}
+
+		if int64(len(output.Routes)) == aws.Int64Value(input.MaxResults) {
+			output.AdditionalRoutesAvailable = aws.Bool(true)
+			break
+		}
+		output.Routes = append(output.Routes, route)
+	}
+	return output, nil
+}
+
+func (m *mockEC2Client) GetTransitGatewayPrefixListReferencesPages(input *ec2.GetTransitGatewayPrefixListReferencesInput, fn func(*ec2.GetTransitGatewayPrefixListReferencesOutput, bool) bool) error {
+	fn(&ec2.GetTransitGatewayPrefixListReferencesOutput{
+		TransitGatewayPrefixListReferences: m.TransitGatewayPrefixListReferences[*input.TransitGatewayRouteTableId],
+	}, true)
+	return m.err
+}
+
+func transitGatewayRoute(cidrBlock, routeType string) *ec2.TransitGatewayRoute {
+	return &ec2.TransitGatewayRoute{
+		DestinationCidrBlock: aws.String(cidrBlock),
+		Type:                 aws.String(routeType),
+		State:                aws.String("active"),
+	}
+}
+
+func testTransitGateways() *ec2.DescribeTransitGatewaysOutput {
+	return &ec2.DescribeTransitGatewaysOutput{
+		TransitGateways: []*ec2.TransitGateway{
+			{
+				TransitGatewayId: aws.String("tgw-hub"),
+				OwnerId:          aws.String("123456789012"),
+				State:            aws.String("available"),
+				Tags:             []*ec2.Tag{{Key: aws.String("team"), Value: aws.String("networking")}},
+			},
+			{
+				TransitGatewayId: aws.String("tgw-deleted"),
+				OwnerId:          aws.String("123456789012"),
+				State:            aws.String("deleted"),
+			},
+			{
+				TransitGatewayId: aws.String("tgw-shared"),
+				OwnerId:          aws.String("210987654321"),
+				State:            aws.String("available"),
+			},
+		},
+	}
+}
+
+func TestTransitGatewayUsageChecksWithError(t *testing.T) {
+	mockClient := &mockEC2Client{
+		err: errors.New("some err"),
+	}
+
+	testCases := []struct {
+		name  string
+		check UsageCheck
+	}{
+		{name: "TransitGatewaysPerAccount", check: &TransitGatewaysPerAccountUsageCheck{client: mockClient, accountID: testAccountID()}},
+		{name: "AttachmentsPerTransitGateway", check: &AttachmentsPerTransitGatewayUsageCheck{client: mockClient, accountID: testAccountID()}},
+		{name: "TransitGatewayRouteTables", check: &TransitGatewayRouteTablesUsageCheck{client: mockClient, accountID: testAccountID()}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			usage, err := tc.check.Usage()
+
+			assert.Error(t, err)
+			assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+			assert.Nil(t, usage)
+		})
+	}
+}
+
+func TestTransitGatewaysPerAccountUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeTransitGatewaysResponse: testTransitGateways(),
+	}
+
+	check := TransitGatewaysPerAccountUsageCheck{client: mockClient, accountID: testAccountID()}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:        transitGatewaysPerAccountName,
+			Description: transitGatewaysPerAccountDesc,
+			Usage:       1,
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
+
+func TestAttachmentsPerTransitGatewayUsage(t *testing.T) {
+	attachment := func(state string) *ec2.TransitGatewayAttachment {
+		return &ec2.TransitGatewayAttachment{TransitGatewayId: aws.String("tgw-hub"), State: aws.String(state)}
+	}
+
+	mockClient := &mockEC2Client{
+		DescribeTransitGatewaysResponse: testTransitGateways(),
+		DescribeTransitGatewayAttachmentsResponse: &ec2.DescribeTransitGatewayAttachmentsOutput{
+			TransitGatewayAttachments: []*ec2.TransitGatewayAttachment{
+				attachment("available"),
+				attachment("pendingAcceptance"),
+				attachment("deleted"),
+				attachment("rejected"),
+			},
+		},
+	}
+
+	check := AttachmentsPerTransitGatewayUsageCheck{client: mockClient, accountID: testAccountID()}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         attachmentsPerTransitGatewayName,
+			ResourceName: aws.String("tgw-hub"),
+			Description:  attachmentsPerTransitGatewayDesc,
+			Usage:        2,
+			Tags:         map[string]string{"team": "networking"},
+			Labels:       map[string]string{"transit_gateway_id": "tgw-hub"},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
+
+func TestTransitGatewayRouteTablesUsage(t *testing.T) {
+	testCases := []struct {
+		name                     string
+		quotas                   []*awsservicequotas.ServiceQuota
+		expectedRouteTablesQuota float64
+		expectedRoutesQuota      float64
+	}{
+		{
+			name:                     "WithDefaultQuotas",
+			expectedRouteTablesQuota: 20,
+			expectedRoutesQuota:      10000,
+		},
+		{
+			name: "WithListedQuotas",
+			quotas: []*awsservicequotas.ServiceQuota{
+				{QuotaName: aws.String("Route tables per transit gateway"), Value: aws.Float64(40)},
+				{QuotaName: aws.String("Routes per transit gateway"), Value: aws.Float64(15000)},
+			},
+			expectedRouteTablesQuota: 40,
+			expectedRoutesQuota:      15000,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := &mockEC2Client{
+				DescribeTransitGatewaysResponse: testTransitGateways(),
+				DescribeTransitGatewayRouteTablesResponse: &ec2.DescribeTransitGatewayRouteTablesOutput{
+					TransitGatewayRouteTables: []*ec2.TransitGatewayRouteTable{
+						{
+							TransitGatewayRouteTableId: aws.String("tgw-rtb-spokes"),
+							TransitGatewayId:           aws.String("tgw-hub"),
+							State:                      aws.String("available"),
+						},
+						{
+							TransitGatewayRouteTableId: aws.String("tgw-rtb-shared"),
+							TransitGatewayId:           aws.String("tgw-hub"),
+							State:                      aws.String("available"),
+						},
+						{
+							TransitGatewayRouteTableId: aws.String("tgw-rtb-deleted"),
+							TransitGatewayId:           aws.String("tgw-hub"),
+							State:                      aws.String("deleted"),
+						},
+					},
+				},
+				TransitGatewayRoutes: map[string][]*ec2.TransitGatewayRoute{
+					"tgw-rtb-spokes": {
+						transitGatewayRoute("10.0.0.0/16", "propagated"),
+						transitGatewayRoute("10.1.0.0/16", "propagated"),
+						transitGatewayRoute("0.0.0.0/0", "static"),
+						{DestinationCidrBlock: aws.String("10.2.0.0/16"), Type: aws.String("propagated"), State: aws.String("deleted")},
+					},
+					"tgw-rtb-shared": {
+						transitGatewayRoute("10.0.0.0/8", "static"),
+					},
+				},
+			}
+
+			check := TransitGatewayRouteTablesUsageCheck{client: mockClient, accountID: testAccountID()}
+			check.setQuotas(tc.quotas)
+			usage, err := check.Usage()
+
+			expectedUsage := []QuotaUsage{
+				{
+					Name:         routeTablesPerTransitGatewayName,
+					ResourceName: aws.String("tgw-hub"),
+					Description:  routeTablesPerTransitGatewayDesc,
+					Usage:        2,
+					Quota:        tc.expectedRouteTablesQuota,
+					Tags:         map[string]string{"team": "networking"},
+					Labels:       map[string]string{"transit_gateway_id": "tgw-hub"},
+				},
+				{
+					Name:         routesPerTransitGatewayName,
+					ResourceName: aws.String("tgw-hub"),
+					Description:  routesPerTransitGatewayDesc,
+					Usage:        4,
+					Quota:        tc.expectedRoutesQuota,
+					Tags:         map[string]string{"team": "networking"},
+					Labels:       map[string]string{"transit_gateway_id": "tgw-hub"},
+				},
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, expectedUsage, usage)
+		})
+	}
+}
+
+func TestTransitGatewayRouteTablesUsageWithTruncatedSearch(t *testing.T) {
+	routes := []*ec2.TransitGatewayRoute{
+		transitGatewayRoute("0.0.0.0/0", "static"),
+		transitGatewayRoute("10.0.0.0/8", "static"),
+		transitGatewayRoute("::/0", "static"),
+		{PrefixListId: aws.String("pl-office"), Type: aws.String("static"), State: aws.String("active")},
+	}
+	for i := 0; i < 2500; i++ {
+		routes = append(routes, transitGatewayRoute(fmt.Sprintf("10.%d.%d.0/24", i/256, i%256), "propagated"))
+	}
+
+	mockClient := &mockEC2Client{
+		DescribeTransitGatewaysResponse: testTransitGateways(),
+		DescribeTransitGatewayRouteTablesResponse: &ec2.DescribeTransitGatewayRouteTablesOutput{
+			TransitGatewayRouteTables: []*ec2.TransitGatewayRouteTable{
+				{
+					TransitGatewayRouteTableId: aws.String("tgw-rtb-spokes"),
+					TransitGatewayId:           aws.String("tgw-hub"),
+					State:                      aws.String("available"),
+				},
+			},
+		},
+		TransitGatewayRoutes: map[string][]*ec2.TransitGatewayRoute{"tgw-rtb-spokes": routes},
+		TransitGatewayPrefixListReferences: map[string][]*ec2.TransitGatewayPrefixListReference{
+			"tgw-rtb-spokes": {{PrefixListId: aws.String("pl-office")}},
+		},
+	}
+
+	check := TransitGatewayRouteTablesUsageCheck{client: mockClient, accountID: testAccountID()}
+	usage, err := check.Usage()
+
+	assert.NoError(t, err)
+	assert.Len(t, usage, 2)
+	assert.Equal(t, routesPerTransitGatewayName, usage[1].Name)
+	assert.Equal(t, float64(len(routes)), usage[1].Usage)
+}
+
+func TestSplitCidrBlock(t *testing.T) {
+	testCases := []struct {
+		cidrBlock      string
+		expectedHalves []string
+	}{
+		{cidrBlock: "0.0.0.0/0", expectedHalves: []string{"0.0.0.0/1", "128.0.0.0/1"}},
+		{cidrBlock: "10.0.0.0/8", expectedHalves: []string{"10.0.0.0/9", "10.128.0.0/9"}},
+		{cidrBlock: "10.0.0.0/15", expectedHalves: []string{"10.0.0.0/16", "10.1.0.0/16"}},
+		{cidrBlock: "::/0", expectedHalves: []string{"::/1", "8000::/1"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cidrBlock, func(t *testing.T) {
+			halves, err := splitCidrBlock(tc.cidrBlock)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedHalves, halves)
+		})
+	}
+
+	_, err := splitCidrBlock("10.0.0.1/32")
+	assert.True(t, errors.Is(err, ErrFailedToConvertCidr))
+}
@@ -0,0 +1,169 @@
+package servicequotas
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+const (
+	interfaceVPCEndpointsPerVPCName = "interface_vpc_endpoints_per_vpc"
+	interfaceVPCEndpointsPerVPCDesc = "interface and Gateway Load Balancer VPC endpoints per VPC"
+
+	gatewayVPCEndpointsPerRegionName = "gateway_vpc_endpoints_per_region"
+	gatewayVPCEndpointsPerRegionDesc = "gateway VPC endpoints per region"
+
+	secGroupsPerVPCEndpointName = "security_groups_per_vpc_endpoint"
+	secGroupsPerVPCEndpointDesc = "security groups per VPC endpoint"
+)
+
+// inactiveVPCEndpointStates are the states of VPC endpoints that do
+// not count against the VPC endpoint quotas. The API reports them in
+// lower case, unlike the SDK's State enum
+var inactiveVPCEndpointStates = map[string]bool{
+	"deleted":  true,
+	"rejected": true,
+	"failed":   true,
+	"expired":  true,
+}
+
+// InterfaceVPCEndpointsPerVPCUsageCheck implements the UsageCheck
+// interface for interface and Gateway Load Balancer VPC endpoints per
+// VPC
+type InterfaceVPCEndpointsPerVPCUsageCheck struct {
+	client ec2iface.EC2API
+	vpcs   *vpcsCache
+}
+
+// Usage returns usage for each VPC ID with the usage value being the
+// number of interface and Gateway Load Balancer endpoints in the VPC
+// or an error
+func (c *InterfaceVPCEndpointsPerVPCUsageCheck) Usage() ([]QuotaUsage, error) {
+	vpcs, err := c.vpcs.OwnedVPCs()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	endpoints, err := describeVPCEndpoints(c.client)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	endpointsPerVPC := map[string]int{}
+	for _, endpoint := range endpoints {
+		switch aws.StringValue(endpoint.VpcEndpointType) {
+		case ec2.VpcEndpointTypeInterface, ec2.VpcEndpointTypeGatewayLoadBalancer:
+			endpointsPerVPC[aws.StringValue(endpoint.VpcId)]++
+		}
+	}
+
+	return vpcCountUsages(interfaceVPCEndpointsPerVPCName, interfaceVPCEndpointsPerVPCDesc, vpcs, endpointsPerVPC), nil
+}
+
+// Definitions returns the interface VPC endpoints per VPC quota
+func (c *InterfaceVPCEndpointsPerVPCUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: interfaceVPCEndpointsPerVPCName, Description: interfaceVPCEndpointsPerVPCDesc}}
+}
+
+// GatewayVPCEndpointsPerRegionUsageCheck implements the UsageCheck
+// interface for gateway VPC endpoints. The quota is scoped to the
+// region rather than to a VPC
+type GatewayVPCEndpointsPerRegionUsageCheck struct {
+	client ec2iface.EC2API
+}
+
+// Usage returns usage for gateway VPC endpoints per region as the
+// number of all gateway endpoints in the region or an error
+func (c *GatewayVPCEndpointsPerRegionUsageCheck) Usage() ([]QuotaUsage, error) {
+	endpoints, err := describeVPCEndpoints(c.client)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	numEndpoints := 0
+	for _, endpoint := range endpoints {
+		if aws.StringValue(endpoint.VpcEndpointType) == ec2.VpcEndpointTypeGateway {
+			numEndpoints++
+		}
+	}
+
+	usage := []QuotaUsage{
+		{
+			Name:        gatewayVPCEndpointsPerRegionName,
+			Description: gatewayVPCEndpointsPerRegionDesc,
+			Usage:       float64(numEndpoints),
+		},
+	}
+	return usage, nil
+}
+
+// Definitions returns the gateway VPC endpoints per region quota
+func (c *GatewayVPCEndpointsPerRegionUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: gatewayVPCEndpointsPerRegionName, Description: gatewayVPCEndpointsPerRegionDesc}}
+}
+
+// SecurityGroupsPerVPCEndpointUsageCheck implements the UsageCheck
+// interface for security groups per interface VPC endpoint. The
+// security groups are applied to the network interfaces of the
+// endpoint, which are already reported against the security groups
+// per network interface quota, so no limit is reported here
+type SecurityGroupsPerVPCEndpointUsageCheck struct {
+	client ec2iface.EC2API
+}
+
+// Usage returns usage for each interface VPC endpoint ID with the
+// usage value being the number of its security groups or an error
+func (c *SecurityGroupsPerVPCEndpointUsageCheck) Usage() ([]QuotaUsage, error) {
+	endpoints, err := describeVPCEndpoints(c.client)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	quotaUsages := []QuotaUsage{}
+	for _, endpoint := range endpoints {
+		if endpoint.VpcEndpointId == nil || aws.StringValue(endpoint.VpcEndpointType) != ec2.VpcEndpointTypeInterface {
+			continue
+		}
+
+		quotaUsages = append(quotaUsages, QuotaUsage{
+			Name:         secGroupsPerVPCEndpointName,
+			ResourceName: endpoint.VpcEndpointId,
+			Description:  secGroupsPerVPCEndpointDesc,
+			Usage:        float64(len(endpoint.Groups)),
+			Tags:         ec2TagsToQuotaUsageTags(endpoint.Tags),
+			Labels:       map[string]string{vpcIDLabel: aws.StringValue(endpoint.VpcId)},
+		})
+	}
+
+	return quotaUsages, nil
+}
+
+// Definitions returns the security groups per VPC endpoint quota
+func (c *SecurityGroupsPerVPCEndpointUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: secGroupsPerVPCEndpointName, Description: secGroupsPerVPCEndpointDesc}}
+}
+
+// describeVPCEndpoints returns the VPC endpoints of the region that
+// count against the VPC endpoint quotas
+func describeVPCEndpoints(client ec2iface.EC2API) ([]*ec2.VpcEndpoint, error) {
+	endpoints := []*ec2.VpcEndpoint{}
+	err := client.DescribeVpcEndpointsPages(&ec2.DescribeVpcEndpointsInput{},
+		func(page *ec2.DescribeVpcEndpointsOutput, lastPage bool) bool {
+			if page != nil {
+				for _, endpoint := range page.VpcEndpoints {
+					if !inactiveVPCEndpointStates[strings.ToLower(aws.StringValue(endpoint.State))] {
+						endpoints = append(endpoints, endpoint)
+					}
+				}
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
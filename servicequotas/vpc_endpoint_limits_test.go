@@ -0,0 +1,151 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func (m *mockEC2Client) DescribeVpcEndpointsPages(input *ec2.DescribeVpcEndpointsInput, fn func(*ec2.DescribeVpcEndpointsOutput, bool) bool) error {
+	fn(m.DescribeVpcEndpointsResponse, true)
+	return m.err
+}
+
+func testVPCEndpoints() *ec2.DescribeVpcEndpointsOutput {
+	return &ec2.DescribeVpcEndpointsOutput{
+		VpcEndpoints: []*ec2.VpcEndpoint{
+			{
+				VpcEndpointId:   aws.String("vpce-interface"),
+				VpcEndpointType: aws.String("Interface"),
+				VpcId:           aws.String("vpc-1"),
+				State:           aws.String("available"),
+				Groups: []*ec2.SecurityGroupIdentifier{
+					{GroupId: aws.String("sg-1")},
+					{GroupId: aws.String("sg-2")},
+				},
+				Tags: []*ec2.Tag{{Key: aws.String("team"), Value: aws.String("networking")}},
+			},
+			{
+				VpcEndpointId:   aws.String("vpce-gwlb"),
+				VpcEndpointType: aws.String("GatewayLoadBalancer"),
+				VpcId:           aws.String("vpc-1"),
+				State:           aws.String("pending"),
+			},
+			{
+				VpcEndpointId:   aws.String("vpce-deleted"),
+				VpcEndpointType: aws.String("Interface"),
+				VpcId:           aws.String("vpc-1"),
+				State:           aws.String("deleted"),
+			},
+			{
+				VpcEndpointId:   aws.String("vpce-s3"),
+				VpcEndpointType: aws.String("Gateway"),
+				VpcId:           aws.String("vpc-2"),
+				State:           aws.String("available"),
+			},
+		},
+	}
+}
+
+func TestVPCEndpointUsageChecksWithError(t *testing.T) {
+	mockClient := &mockEC2Client{
+		err: errors.New("some err"),
+	}
+
+	testCases := []struct {
+		name  string
+		check UsageCheck
+	}{
+		{name: "InterfaceVPCEndpointsPerVPC", check: &InterfaceVPCEndpointsPerVPCUsageCheck{client: mockClient, vpcs: newVPCsCache(mockClient, testAccountID())}},
+		{name: "GatewayVPCEndpointsPerRegion", check: &GatewayVPCEndpointsPerRegionUsageCheck{mockClient}},
+		{name: "SecurityGroupsPerVPCEndpoint", check: &SecurityGroupsPerVPCEndpointUsageCheck{mockClient}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			usage, err := tc.check.Usage()
+
+			assert.Error(t, err)
+			assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+			assert.Nil(t, usage)
+		})
+	}
+}
+
+func TestInterfaceVPCEndpointsPerVPCUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeVpcsResponse: &ec2.DescribeVpcsOutput{
+			Vpcs: []*ec2.Vpc{{VpcId: aws.String("vpc-1"), OwnerId: aws.String("123456789012")}, {VpcId: aws.String("vpc-2"), OwnerId: aws.String("123456789012")}},
+		},
+		DescribeVpcEndpointsResponse: testVPCEndpoints(),
+	}
+
+	check := InterfaceVPCEndpointsPerVPCUsageCheck{client: mockClient, vpcs: newVPCsCache(mockClient, testAccountID())}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         interfaceVPCEndpointsPerVPCName,
+			ResourceName: aws.String("vpc-1"),
+			Description:  interfaceVPCEndpointsPerVPCDesc,
+			Usage:        2,
+			Labels:       map[string]string{"vpc_id": "vpc-1"},
+		},
+		{
+			Name:         interfaceVPCEndpointsPerVPCName,
+			ResourceName: aws.String("vpc-2"),
+			Description:  interfaceVPCEndpointsPerVPCDesc,
+			Usage:        0,
+			Labels:       map[string]string{"vpc_id": "vpc-2"},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
+
+func TestGatewayVPCEndpointsPerRegionUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeVpcEndpointsResponse: testVPCEndpoints(),
+	}
+
+	check := GatewayVPCEndpointsPerRegionUsageCheck{mockClient}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:        gatewayVPCEndpointsPerRegionName,
+			Description: gatewayVPCEndpointsPerRegionDesc,
+			Usage:       1,
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
+
+func TestSecurityGroupsPerVPCEndpointUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeVpcEndpointsResponse: testVPCEndpoints(),
+	}
+
+	check := SecurityGroupsPerVPCEndpointUsageCheck{mockClient}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         secGroupsPerVPCEndpointName,
+			ResourceName: aws.String("vpce-interface"),
+			Description:  secGroupsPerVPCEndpointDesc,
+			Usage:        2,
+			Tags:         map[string]string{"team": "networking"},
+			Labels:       map[string]string{"vpc_id": "vpc-1"},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
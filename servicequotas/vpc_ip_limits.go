@@ -0,0 +1,164 @@
+package servicequotas
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const (
+	availableIPsPerVPCName = "available_ips_per_vpc"
+	availableIPsPerVPCDesc = "available IPs per VPC"
+
+	availableIPsPerAZName = "available_ips_per_availability_zone"
+	availableIPsPerAZDesc = "available IPs per VPC and availability zone"
+
+	vpcIDLabel            = "vpc_id"
+	availabilityZoneLabel = "availability_zone"
+)
+
+// AvailableIpsPerVPCUsageCheck implements the UsageCheckInterface
+// for the IPv4 address space of VPCs, aggregated per VPC and per VPC
+// and availability zone
+type AvailableIpsPerVPCUsageCheck struct {
+	vpcs *vpcsCache
+}
+
+// vpcIPUsage is the IPv4 address usage of a VPC or an availability
+// zone of a VPC
+type vpcIPUsage struct {
+	vpcID            string
+	availabilityZone string
+	used             float64
+	total            float64
+}
+
+// Usage returns the IPv4 address usage of every VPC and every
+// availability zone of a VPC or an error. The VPC quota is the size
+// of all CIDR blocks associated to the VPC, including secondary ones,
+// and the usage the addresses that are used or reserved in its
+// subnets. The availability zone quota is the size of the subnets in
+// the availability zone
+func (c *AvailableIpsPerVPCUsageCheck) Usage() ([]QuotaUsage, error) {
+	vpcs, err := c.vpcs.VPCs()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	subnets, err := c.vpcs.Subnets()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	vpcUsages := map[string]*vpcIPUsage{}
+	vpcTags := map[string]map[string]string{}
+	for _, vpc := range vpcs {
+		usage := &vpcIPUsage{vpcID: *vpc.VpcId}
+		for _, cidrBlock := range associatedVPCCidrBlocks(vpc) {
+			size, err := cidrBlockSize(cidrBlock)
+			if err != nil {
+				return nil, err
+			}
+			usage.total += size
+		}
+		vpcUsages[*vpc.VpcId] = usage
+		vpcTags[*vpc.VpcId] = ec2TagsToQuotaUsageTags(vpc.Tags)
+	}
+
+	azUsages := map[string]*vpcIPUsage{}
+	for _, subnet := range subnets {
+		// IPv6-only subnets don't use any of the IPv4 address space
+		if subnet.VpcId == nil || subnet.AvailabilityZone == nil || subnet.CidrBlock == nil {
+			continue
+		}
+
+		size, err := cidrBlockSize(*subnet.CidrBlock)
+		if err != nil {
+			return nil, err
+		}
+		used := size - float64(*subnet.AvailableIpAddressCount)
+
+		if vpcUsage, ok := vpcUsages[*subnet.VpcId]; ok {
+			vpcUsage.used += used
+		}
+
+		key := availabilityZoneResourceName(*subnet.VpcId, *subnet.AvailabilityZone)
+		azUsage, ok := azUsages[key]
+		if !ok {
+			azUsage = &vpcIPUsage{vpcID: *subnet.VpcId, availabilityZone: *subnet.AvailabilityZone}
+			azUsages[key] = azUsage
+		}
+		azUsage.used += used
+		azUsage.total += size
+	}
+
+	quotaUsages := []QuotaUsage{}
+	for _, vpc := range vpcs {
+		usage := vpcUsages[*vpc.VpcId]
+		quotaUsages = append(quotaUsages, QuotaUsage{
+			Name:         availableIPsPerVPCName,
+			ResourceName: vpc.VpcId,
+			Description:  availableIPsPerVPCDesc,
+			Usage:        usage.used,
+			Quota:        usage.total,
+			Tags:         vpcTags[usage.vpcID],
+			Labels:       map[string]string{vpcIDLabel: usage.vpcID},
+		})
+	}
+
+	keys := make([]string, 0, len(azUsages))
+	for key := range azUsages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		resourceName := key
+		usage := azUsages[key]
+		quotaUsages = append(quotaUsages, QuotaUsage{
+			Name:         availableIPsPerAZName,
+			ResourceName: &resourceName,
+			Description:  availableIPsPerAZDesc,
+			Usage:        usage.used,
+			Quota:        usage.total,
+			Tags:         vpcTags[usage.vpcID],
+			Labels: map[string]string{
+				vpcIDLabel:            usage.vpcID,
+				availabilityZoneLabel: usage.availabilityZone,
+			},
+		})
+	}
+
+	return quotaUsages, nil
+}
+
+// Definitions returns the available IPs per VPC and per availability
+// zone information
+func (c *AvailableIpsPerVPCUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{
+		{Name: availableIPsPerVPCName, Description: availableIPsPerVPCDesc},
+		{Name: availableIPsPerAZName, Description: availableIPsPerAZDesc},
+	}
+}
+
+func availabilityZoneResourceName(vpcID, availabilityZone string) string {
+	return fmt.Sprintf("%s/%s", vpcID, availabilityZone)
+}
+
+// associatedVPCCidrBlocks returns the primary and secondary IPv4 CIDR
+// blocks associated to `vpc`
+func associatedVPCCidrBlocks(vpc *ec2.Vpc) []string {
+	cidrBlocks := []string{}
+	for _, association := range vpc.CidrBlockAssociationSet {
+		state := association.CidrBlockState
+		if association.CidrBlock != nil && state != nil && state.State != nil && *state.State == associatedCidrBlockState {
+			cidrBlocks = append(cidrBlocks, *association.CidrBlock)
+		}
+	}
+
+	if len(cidrBlocks) == 0 && vpc.CidrBlock != nil {
+		cidrBlocks = append(cidrBlocks, *vpc.CidrBlock)
+	}
+	return cidrBlocks
+}
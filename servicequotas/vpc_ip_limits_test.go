@@ -0,0 +1,135 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func (m *mockEC2Client) DescribeVpcsPages(input *ec2.DescribeVpcsInput, fn func(*ec2.DescribeVpcsOutput, bool) bool) error {
+	m.DescribeVpcsCalls++
+	fn(m.DescribeVpcsResponse, true)
+	return m.err
+}
+
+func vpcCidrBlockAssociation(cidrBlock, state string) *ec2.VpcCidrBlockAssociation {
+	return &ec2.VpcCidrBlockAssociation{
+		CidrBlock:      aws.String(cidrBlock),
+		CidrBlockState: &ec2.VpcCidrBlockState{State: aws.String(state)},
+	}
+}
+
+func TestAvailableIpsPerVPCUsageWithError(t *testing.T) {
+	mockClient := &mockEC2Client{
+		err: errors.New("some err"),
+	}
+
+	check := AvailableIpsPerVPCUsageCheck{newVPCsCache(mockClient, testAccountID())}
+	usage, err := check.Usage()
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+	assert.Nil(t, usage)
+}
+
+func TestAvailableIpsPerVPCUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeVpcsResponse: &ec2.DescribeVpcsOutput{
+			Vpcs: []*ec2.Vpc{
+				{
+					VpcId:     aws.String("vpc-1"),
+					CidrBlock: aws.String("10.0.0.0/22"),
+					CidrBlockAssociationSet: []*ec2.VpcCidrBlockAssociation{
+						vpcCidrBlockAssociation("10.0.0.0/22", "associated"),
+						vpcCidrBlockAssociation("100.64.0.0/22", "associated"),
+						vpcCidrBlockAssociation("100.65.0.0/16", "disassociated"),
+					},
+					Tags: []*ec2.Tag{{Key: aws.String("team"), Value: aws.String("networking")}},
+				},
+				{
+					VpcId:     aws.String("vpc-2"),
+					CidrBlock: aws.String("10.1.0.0/24"),
+				},
+			},
+		},
+		DescribeSubnetsResponse: &ec2.DescribeSubnetsOutput{
+			Subnets: []*ec2.Subnet{
+				{
+					VpcId:                   aws.String("vpc-1"),
+					AvailabilityZone:        aws.String("eu-west-1a"),
+					AvailableIpAddressCount: aws.Int64(200),
+					CidrBlock:               aws.String("10.0.0.0/24"),
+					SubnetId:                aws.String("subnet-1"),
+				},
+				{
+					VpcId:                   aws.String("vpc-1"),
+					AvailabilityZone:        aws.String("eu-west-1a"),
+					AvailableIpAddressCount: aws.Int64(1000),
+					CidrBlock:               aws.String("100.64.0.0/22"),
+					SubnetId:                aws.String("subnet-2"),
+				},
+				{
+					VpcId:                   aws.String("vpc-1"),
+					AvailabilityZone:        aws.String("eu-west-1b"),
+					AvailableIpAddressCount: aws.Int64(0),
+					CidrBlock:               aws.String("10.0.1.0/24"),
+					SubnetId:                aws.String("subnet-3"),
+				},
+				{
+					VpcId:                   aws.String("vpc-2"),
+					AvailabilityZone:        aws.String("eu-west-1c"),
+					AvailableIpAddressCount: aws.Int64(0),
+					SubnetId:                aws.String("subnet-ipv6only"),
+					Ipv6Native:              aws.Bool(true),
+				},
+			},
+		},
+	}
+
+	check := AvailableIpsPerVPCUsageCheck{newVPCsCache(mockClient, testAccountID())}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         availableIPsPerVPCName,
+			ResourceName: aws.String("vpc-1"),
+			Description:  availableIPsPerVPCDesc,
+			Usage:        56 + 24 + 256,
+			Quota:        2048,
+			Tags:         map[string]string{"team": "networking"},
+			Labels:       map[string]string{"vpc_id": "vpc-1"},
+		},
+		{
+			Name:         availableIPsPerVPCName,
+			ResourceName: aws.String("vpc-2"),
+			Description:  availableIPsPerVPCDesc,
+			Usage:        0,
+			Quota:        256,
+			Labels:       map[string]string{"vpc_id": "vpc-2"},
+		},
+		{
+			Name:         availableIPsPerAZName,
+			ResourceName: aws.String("vpc-1/eu-west-1a"),
+			Description:  availableIPsPerAZDesc,
+			Usage:        56 + 24,
+			Quota:        256 + 1024,
+			Tags:         map[string]string{"team": "networking"},
+			Labels:       map[string]string{"vpc_id": "vpc-1", "availability_zone": "eu-west-1a"},
+		},
+		{
+			Name:         availableIPsPerAZName,
+			ResourceName: aws.String("vpc-1/eu-west-1b"),
+			Description:  availableIPsPerAZDesc,
+			Usage:        256,
+			Quota:        256,
+			Tags:         map[string]string{"team": "networking"},
+			Labels:       map[string]string{"vpc_id": "vpc-1", "availability_zone": "eu-west-1b"},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
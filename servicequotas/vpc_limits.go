@@ -0,0 +1,330 @@
+package servicequotas
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+const (
+	vpcsPerRegionName = "vpcs_per_region"
+	vpcsPerRegionDesc = "VPCs per region"
+
+	subnetsPerVPCName = "subnets_per_vpc"
+	subnetsPerVPCDesc = "subnets per VPC"
+
+	internetGatewaysPerRegionName = "internet_gateways_per_region"
+	internetGatewaysPerRegionDesc = "internet gateways per region"
+
+	natGatewaysPerAZName = "nat_gateways_per_availability_zone"
+	natGatewaysPerAZDesc = "NAT gateways per availability zone"
+
+	egressOnlyInternetGatewaysPerRegionName = "egress_only_internet_gateways_per_region"
+	egressOnlyInternetGatewaysPerRegionDesc = "egress-only internet gateways per region"
+
+	vpcPeeringConnectionsPerVPCName = "vpc_peering_connections_per_vpc"
+	vpcPeeringConnectionsPerVPCDesc = "active VPC peering connections per VPC"
+
+	activeVPCPeeringConnectionState = "active"
+)
+
+// natGatewayStates are the states of NAT gateways that count against
+// the NAT gateways per availability zone quota
+var natGatewayStates = []string{"pending", "available", "deleting"}
+
+// VPCsPerRegionUsageCheck implements the UsageCheck interface for
+// VPCs per region
+type VPCsPerRegionUsageCheck struct {
+	vpcs *vpcsCache
+}
+
+// Usage returns usage for VPCs per region as the number of VPCs
+// owned by the account in the region or an error
+func (c *VPCsPerRegionUsageCheck) Usage() ([]QuotaUsage, error) {
+	vpcs, err := c.vpcs.OwnedVPCs()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	usage := []QuotaUsage{
+		{
+			Name:        vpcsPerRegionName,
+			Description: vpcsPerRegionDesc,
+			Usage:       float64(len(vpcs)),
+		},
+	}
+	return usage, nil
+}
+
+// Definitions returns the VPCs per region quota
+func (c *VPCsPerRegionUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: vpcsPerRegionName, Description: vpcsPerRegionDesc}}
+}
+
+// SubnetsPerVPCUsageCheck implements the UsageCheck interface for
+// subnets per VPC
+type SubnetsPerVPCUsageCheck struct {
+	vpcs *vpcsCache
+}
+
+// Usage returns usage for each VPC ID with the usage value being the
+// number of subnets in the VPC or an error
+func (c *SubnetsPerVPCUsageCheck) Usage() ([]QuotaUsage, error) {
+	vpcs, err := c.vpcs.OwnedVPCs()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	subnets, err := c.vpcs.Subnets()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	subnetsPerVPC := map[string]int{}
+	for _, subnet := range subnets {
+		subnetsPerVPC[aws.StringValue(subnet.VpcId)]++
+	}
+
+	return vpcCountUsages(subnetsPerVPCName, subnetsPerVPCDesc, vpcs, subnetsPerVPC), nil
+}
+
+// Definitions returns the subnets per VPC quota
+func (c *SubnetsPerVPCUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: subnetsPerVPCName, Description: subnetsPerVPCDesc}}
+}
+
+// InternetGatewaysPerRegionUsageCheck implements the UsageCheck
+// interface for internet gateways per region
+type InternetGatewaysPerRegionUsageCheck struct {
+	client ec2iface.EC2API
+}
+
+// Usage returns usage for internet gateways per region as the number
+// of all internet gateways in the region or an error
+func (c *InternetGatewaysPerRegionUsageCheck) Usage() ([]QuotaUsage, error) {
+	numGateways := 0
+
+	params := &ec2.DescribeInternetGatewaysInput{}
+	err := c.client.DescribeInternetGatewaysPages(params,
+		func(page *ec2.DescribeInternetGatewaysOutput, lastPage bool) bool {
+			if page != nil {
+				numGateways += len(page.InternetGateways)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	usage := []QuotaUsage{
+		{
+			Name:        internetGatewaysPerRegionName,
+			Description: internetGatewaysPerRegionDesc,
+			Usage:       float64(numGateways),
+		},
+	}
+	return usage, nil
+}
+
+// Definitions returns the internet gateways per region quota
+func (c *InternetGatewaysPerRegionUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: internetGatewaysPerRegionName, Description: internetGatewaysPerRegionDesc}}
+}
+
+// NATGatewaysPerAZUsageCheck implements the UsageCheck interface for
+// NAT gateways per availability zone
+type NATGatewaysPerAZUsageCheck struct {
+	client ec2iface.EC2API
+	vpcs   *vpcsCache
+}
+
+// Usage returns usage for each availability zone with NAT gateways
+// with the usage value being the number of pending, available and
+// deleting NAT gateways in subnets of the availability zone or an
+// error
+func (c *NATGatewaysPerAZUsageCheck) Usage() ([]QuotaUsage, error) {
+	gateways := []*ec2.NatGateway{}
+
+	params := &ec2.DescribeNatGatewaysInput{
+		Filter: []*ec2.Filter{
+			{
+				Name:   aws.String("state"),
+				Values: aws.StringSlice(natGatewayStates),
+			},
+		},
+	}
+	err := c.client.DescribeNatGatewaysPages(params,
+		func(page *ec2.DescribeNatGatewaysOutput, lastPage bool) bool {
+			if page != nil {
+				gateways = append(gateways, page.NatGateways...)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	subnets, err := c.vpcs.Subnets()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	subnetAZs := map[string]string{}
+	for _, subnet := range subnets {
+		subnetAZs[aws.StringValue(subnet.SubnetId)] = aws.StringValue(subnet.AvailabilityZone)
+	}
+
+	gatewaysPerAZ := map[string]int{}
+	for _, gateway := range gateways {
+		if az, ok := subnetAZs[aws.StringValue(gateway.SubnetId)]; ok && az != "" {
+			gatewaysPerAZ[az]++
+		}
+	}
+
+	availabilityZones := make([]string, 0, len(gatewaysPerAZ))
+	for az := range gatewaysPerAZ {
+		availabilityZones = append(availabilityZones, az)
+	}
+	sort.Strings(availabilityZones)
+
+	quotaUsages := []QuotaUsage{}
+	for _, az := range availabilityZones {
+		resourceName := az
+		quotaUsages = append(quotaUsages, QuotaUsage{
+			Name:         natGatewaysPerAZName,
+			ResourceName: &resourceName,
+			Description:  natGatewaysPerAZDesc,
+			Usage:        float64(gatewaysPerAZ[az]),
+			Labels:       map[string]string{availabilityZoneLabel: az},
+		})
+	}
+
+	return quotaUsages, nil
+}
+
+// Definitions returns the NAT gateways per availability zone quota
+func (c *NATGatewaysPerAZUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: natGatewaysPerAZName, Description: natGatewaysPerAZDesc}}
+}
+
+// EgressOnlyInternetGatewaysPerRegionUsageCheck implements the
+// UsageCheck interface for egress-only internet gateways per region
+type EgressOnlyInternetGatewaysPerRegionUsageCheck struct {
+	client ec2iface.EC2API
+}
+
+// Usage returns usage for egress-only internet gateways per region as
+// the number of all egress-only internet gateways in the region or an
+// error
+func (c *EgressOnlyInternetGatewaysPerRegionUsageCheck) Usage() ([]QuotaUsage, error) {
+	numGateways := 0
+
+	params := &ec2.DescribeEgressOnlyInternetGatewaysInput{}
+	err := c.client.DescribeEgressOnlyInternetGatewaysPages(params,
+		func(page *ec2.DescribeEgressOnlyInternetGatewaysOutput, lastPage bool) bool {
+			if page != nil {
+				numGateways += len(page.EgressOnlyInternetGateways)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	usage := []QuotaUsage{
+		{
+			Name:        egressOnlyInternetGatewaysPerRegionName,
+			Description: egressOnlyInternetGatewaysPerRegionDesc,
+			Usage:       float64(numGateways),
+		},
+	}
+	return usage, nil
+}
+
+// Definitions returns the egress-only internet gateways per region
+// quota
+func (c *EgressOnlyInternetGatewaysPerRegionUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: egressOnlyInternetGatewaysPerRegionName, Description: egressOnlyInternetGatewaysPerRegionDesc}}
+}
+
+// VPCPeeringConnectionsPerVPCUsageCheck implements the UsageCheck
+// interface for active VPC peering connections per VPC
+type VPCPeeringConnectionsPerVPCUsageCheck struct {
+	client ec2iface.EC2API
+	vpcs   *vpcsCache
+}
+
+// Usage returns usage for each VPC ID with the usage value being the
+// number of active peering connections the VPC is the requester or
+// the accepter of or an error
+func (c *VPCPeeringConnectionsPerVPCUsageCheck) Usage() ([]QuotaUsage, error) {
+	vpcs, err := c.vpcs.OwnedVPCs()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	peeringConnectionsPerVPC := map[string]int{}
+
+	params := &ec2.DescribeVpcPeeringConnectionsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("status-code"),
+				Values: []*string{aws.String(activeVPCPeeringConnectionState)},
+			},
+		},
+	}
+	err = c.client.DescribeVpcPeeringConnectionsPages(params,
+		func(page *ec2.DescribeVpcPeeringConnectionsOutput, lastPage bool) bool {
+			if page != nil {
+				for _, connection := range page.VpcPeeringConnections {
+					if connection.RequesterVpcInfo != nil {
+						peeringConnectionsPerVPC[aws.StringValue(connection.RequesterVpcInfo.VpcId)]++
+					}
+					if connection.AccepterVpcInfo != nil {
+						peeringConnectionsPerVPC[aws.StringValue(connection.AccepterVpcInfo.VpcId)]++
+					}
+				}
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFailedToGetUsage, err)
+	}
+
+	return vpcCountUsages(vpcPeeringConnectionsPerVPCName, vpcPeeringConnectionsPerVPCDesc, vpcs, peeringConnectionsPerVPC), nil
+}
+
+// Definitions returns the active VPC peering connections per VPC quota
+func (c *VPCPeeringConnectionsPerVPCUsageCheck) Definitions() []QuotaDefinition {
+	return []QuotaDefinition{{Name: vpcPeeringConnectionsPerVPCName, Description: vpcPeeringConnectionsPerVPCDesc}}
+}
+
+// vpcCountUsages returns usage for each VPC in `vpcs`, which should
+// only hold the VPCs owned by the account, with the usage
+// value being its count in `counts`, labelled with the VPC ID and
+// tagged with the VPC's tags
+func vpcCountUsages(name, description string, vpcs []*ec2.Vpc, counts map[string]int) []QuotaUsage {
+	quotaUsages := []QuotaUsage{}
+	for _, vpc := range vpcs {
+		if vpc.VpcId == nil {
+			continue
+		}
+
+		quotaUsages = append(quotaUsages, QuotaUsage{
+			Name:         name,
+			ResourceName: vpc.VpcId,
+			Description:  description,
+			Usage:        float64(counts[*vpc.VpcId]),
+			Tags:         ec2TagsToQuotaUsageTags(vpc.Tags),
+			Labels:       map[string]string{vpcIDLabel: *vpc.VpcId},
+		})
+	}
+	return quotaUsages
+}
@@ -0,0 +1,299 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func (m *mockEC2Client) DescribeInternetGatewaysPages(input *ec2.DescribeInternetGatewaysInput, fn func(*ec2.DescribeInternetGatewaysOutput, bool) bool) error {
+	fn(m.DescribeInternetGatewaysResponse, true)
+	return m.err
+}
+
+func (m *mockEC2Client) DescribeNatGatewaysPages(input *ec2.DescribeNatGatewaysInput, fn func(*ec2.DescribeNatGatewaysOutput, bool) bool) error {
+	m.NatGatewaysFilters = input.Filter
+	fn(m.DescribeNatGatewaysResponse, true)
+	return m.err
+}
+
+func (m *mockEC2Client) DescribeEgressOnlyInternetGatewaysPages(input *ec2.DescribeEgressOnlyInternetGatewaysInput, fn func(*ec2.DescribeEgressOnlyInternetGatewaysOutput, bool) bool) error {
+	fn(m.DescribeEgressOnlyInternetGatewaysResponse, true)
+	return m.err
+}
+
+func (m *mockEC2Client) DescribeVpcPeeringConnectionsPages(input *ec2.DescribeVpcPeeringConnectionsInput, fn func(*ec2.DescribeVpcPeeringConnectionsOutput, bool) bool) error {
+	m.VpcPeeringConnectionsFilters = input.Filters
+	fn(m.DescribeVpcPeeringConnectionsResponse, true)
+	return m.err
+}
+
+func TestVPCUsageChecksWithError(t *testing.T) {
+	mockClient := &mockEC2Client{
+		err: errors.New("some err"),
+	}
+
+	testCases := []struct {
+		name  string
+		check UsageCheck
+	}{
+		{name: "VPCsPerRegion", check: &VPCsPerRegionUsageCheck{newVPCsCache(mockClient, testAccountID())}},
+		{name: "SubnetsPerVPC", check: &SubnetsPerVPCUsageCheck{newVPCsCache(mockClient, testAccountID())}},
+		{name: "InternetGatewaysPerRegion", check: &InternetGatewaysPerRegionUsageCheck{mockClient}},
+		{name: "NATGatewaysPerAZ", check: &NATGatewaysPerAZUsageCheck{client: mockClient, vpcs: newVPCsCache(mockClient, testAccountID())}},
+		{name: "EgressOnlyInternetGatewaysPerRegion", check: &EgressOnlyInternetGatewaysPerRegionUsageCheck{mockClient}},
+		{name: "VPCPeeringConnectionsPerVPC", check: &VPCPeeringConnectionsPerVPCUsageCheck{client: mockClient, vpcs: newVPCsCache(mockClient, testAccountID())}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			usage, err := tc.check.Usage()
+
+			assert.Error(t, err)
+			assert.True(t, errors.Is(err, ErrFailedToGetUsage))
+			assert.Nil(t, usage)
+		})
+	}
+}
+
+func TestVPCsPerRegionUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeVpcsResponse: &ec2.DescribeVpcsOutput{
+			Vpcs: []*ec2.Vpc{
+				{VpcId: aws.String("vpc-1"), OwnerId: aws.String("123456789012")},
+				{VpcId: aws.String("vpc-2"), OwnerId: aws.String("123456789012")},
+				{VpcId: aws.String("vpc-shared"), OwnerId: aws.String("210987654321")},
+			},
+		},
+	}
+
+	check := VPCsPerRegionUsageCheck{newVPCsCache(mockClient, testAccountID())}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:        vpcsPerRegionName,
+			Description: vpcsPerRegionDesc,
+			Usage:       2,
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
+
+func TestSubnetsPerVPCUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeVpcsResponse: &ec2.DescribeVpcsOutput{
+			Vpcs: []*ec2.Vpc{
+				{
+					VpcId:   aws.String("vpc-1"),
+					OwnerId: aws.String("123456789012"),
+					Tags:    []*ec2.Tag{{Key: aws.String("team"), Value: aws.String("networking")}},
+				},
+				{
+					VpcId:   aws.String("vpc-2"),
+					OwnerId: aws.String("123456789012"),
+				},
+				{
+					VpcId:   aws.String("vpc-shared"),
+					OwnerId: aws.String("210987654321"),
+				},
+			},
+		},
+		DescribeSubnetsResponse: &ec2.DescribeSubnetsOutput{
+			Subnets: []*ec2.Subnet{
+				{SubnetId: aws.String("subnet-1"), VpcId: aws.String("vpc-1")},
+				{SubnetId: aws.String("subnet-2"), VpcId: aws.String("vpc-1")},
+				{SubnetId: aws.String("subnet-3"), VpcId: aws.String("vpc-shared")},
+			},
+		},
+	}
+
+	check := SubnetsPerVPCUsageCheck{newVPCsCache(mockClient, testAccountID())}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         subnetsPerVPCName,
+			ResourceName: aws.String("vpc-1"),
+			Description:  subnetsPerVPCDesc,
+			Usage:        2,
+			Tags:         map[string]string{"team": "networking"},
+			Labels:       map[string]string{"vpc_id": "vpc-1"},
+		},
+		{
+			Name:         subnetsPerVPCName,
+			ResourceName: aws.String("vpc-2"),
+			Description:  subnetsPerVPCDesc,
+			Usage:        0,
+			Labels:       map[string]string{"vpc_id": "vpc-2"},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
+
+func TestInternetGatewaysPerRegionUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeInternetGatewaysResponse: &ec2.DescribeInternetGatewaysOutput{
+			InternetGateways: []*ec2.InternetGateway{{InternetGatewayId: aws.String("igw-1")}},
+		},
+	}
+
+	check := InternetGatewaysPerRegionUsageCheck{mockClient}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:        internetGatewaysPerRegionName,
+			Description: internetGatewaysPerRegionDesc,
+			Usage:       1,
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
+
+func TestNATGatewaysPerAZUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeNatGatewaysResponse: &ec2.DescribeNatGatewaysOutput{
+			NatGateways: []*ec2.NatGateway{
+				{NatGatewayId: aws.String("nat-1"), SubnetId: aws.String("subnet-1")},
+				{NatGatewayId: aws.String("nat-2"), SubnetId: aws.String("subnet-2")},
+				{NatGatewayId: aws.String("nat-3"), SubnetId: aws.String("subnet-3")},
+				{NatGatewayId: aws.String("nat-4"), SubnetId: aws.String("subnet-unknown")},
+			},
+		},
+		DescribeSubnetsResponse: &ec2.DescribeSubnetsOutput{
+			Subnets: []*ec2.Subnet{
+				{SubnetId: aws.String("subnet-1"), AvailabilityZone: aws.String("eu-west-1b")},
+				{SubnetId: aws.String("subnet-2"), AvailabilityZone: aws.String("eu-west-1a")},
+				{SubnetId: aws.String("subnet-3"), AvailabilityZone: aws.String("eu-west-1b")},
+			},
+		},
+	}
+
+	check := NATGatewaysPerAZUsageCheck{client: mockClient, vpcs: newVPCsCache(mockClient, testAccountID())}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         natGatewaysPerAZName,
+			ResourceName: aws.String("eu-west-1a"),
+			Description:  natGatewaysPerAZDesc,
+			Usage:        1,
+			Labels:       map[string]string{"availability_zone": "eu-west-1a"},
+		},
+		{
+			Name:         natGatewaysPerAZName,
+			ResourceName: aws.String("eu-west-1b"),
+			Description:  natGatewaysPerAZDesc,
+			Usage:        2,
+			Labels:       map[string]string{"availability_zone": "eu-west-1b"},
+		},
+	}
+	expectedFilters := []*ec2.Filter{
+		{
+			Name:   aws.String("state"),
+			Values: aws.StringSlice([]string{"pending", "available", "deleting"}),
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+	assert.Equal(t, expectedFilters, mockClient.NatGatewaysFilters)
+}
+
+func TestEgressOnlyInternetGatewaysPerRegionUsage(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeEgressOnlyInternetGatewaysResponse: &ec2.DescribeEgressOnlyInternetGatewaysOutput{
+			EgressOnlyInternetGateways: []*ec2.EgressOnlyInternetGateway{
+				{EgressOnlyInternetGatewayId: aws.String("eigw-1")},
+				{EgressOnlyInternetGatewayId: aws.String("eigw-2")},
+			},
+		},
+	}
+
+	check := EgressOnlyInternetGatewaysPerRegionUsageCheck{mockClient}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:        egressOnlyInternetGatewaysPerRegionName,
+			Description: egressOnlyInternetGatewaysPerRegionDesc,
+			Usage:       2,
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+}
+
+func TestVPCPeeringConnectionsPerVPCUsage(t *testing.T) {
+	peeringConnection := func(requesterVPCID, accepterVPCID string) *ec2.VpcPeeringConnection {
+		return &ec2.VpcPeeringConnection{
+			RequesterVpcInfo: &ec2.VpcPeeringConnectionVpcInfo{VpcId: aws.String(requesterVPCID)},
+			AccepterVpcInfo:  &ec2.VpcPeeringConnectionVpcInfo{VpcId: aws.String(accepterVPCID)},
+		}
+	}
+
+	mockClient := &mockEC2Client{
+		DescribeVpcsResponse: &ec2.DescribeVpcsOutput{
+			Vpcs: []*ec2.Vpc{
+				{
+					VpcId:   aws.String("vpc-hub"),
+					OwnerId: aws.String("123456789012"),
+					Tags:    []*ec2.Tag{{Key: aws.String("team"), Value: aws.String("networking")}},
+				},
+				{
+					VpcId:   aws.String("vpc-1"),
+					OwnerId: aws.String("123456789012"),
+				},
+			},
+		},
+		DescribeVpcPeeringConnectionsResponse: &ec2.DescribeVpcPeeringConnectionsOutput{
+			VpcPeeringConnections: []*ec2.VpcPeeringConnection{
+				peeringConnection("vpc-1", "vpc-hub"),
+				peeringConnection("vpc-hub", "vpc-other-region"),
+				peeringConnection("vpc-other-account", "vpc-hub"),
+			},
+		},
+	}
+
+	check := VPCPeeringConnectionsPerVPCUsageCheck{client: mockClient, vpcs: newVPCsCache(mockClient, testAccountID())}
+	usage, err := check.Usage()
+
+	expectedUsage := []QuotaUsage{
+		{
+			Name:         vpcPeeringConnectionsPerVPCName,
+			ResourceName: aws.String("vpc-hub"),
+			Description:  vpcPeeringConnectionsPerVPCDesc,
+			Usage:        3,
+			Tags:         map[string]string{"team": "networking"},
+			Labels:       map[string]string{"vpc_id": "vpc-hub"},
+		},
+		{
+			Name:         vpcPeeringConnectionsPerVPCName,
+			ResourceName: aws.String("vpc-1"),
+			Description:  vpcPeeringConnectionsPerVPCDesc,
+			Usage:        1,
+			Labels:       map[string]string{"vpc_id": "vpc-1"},
+		},
+	}
+	expectedFilters := []*ec2.Filter{
+		{
+			Name:   aws.String("status-code"),
+			Values: []*string{aws.String("active")},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+	assert.Equal(t, expectedFilters, mockClient.VpcPeeringConnectionsFilters)
+}
@@ -0,0 +1,160 @@
+package servicequotas
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// vpcsCache caches the VPCs, subnets and route tables so usage
+// checks based on them don't describe them separately
+type vpcsCache struct {
+	client                 ec2iface.EC2API
+	accountID              *accountIDCache
+	ttl                    time.Duration
+	lock                   sync.Mutex
+	vpcs                   []*ec2.Vpc
+	vpcsLastRefresh        time.Time
+	subnets                []*ec2.Subnet
+	subnetsLastRefresh     time.Time
+	routeTables            []*ec2.RouteTable
+	routeTablesLastRefresh time.Time
+}
+
+func newVPCsCache(client ec2iface.EC2API, accountID *accountIDCache) *vpcsCache {
+	return &vpcsCache{client: client, accountID: accountID, ttl: instancesCacheTTL}
+}
+
+// VPCs returns the VPCs, describing them if the cached VPCs are older
+// than the cache's TTL, or an error
+func (c *vpcsCache) VPCs() ([]*ec2.Vpc, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.vpcs != nil && time.Since(c.vpcsLastRefresh) < c.ttl {
+		return c.vpcs, nil
+	}
+
+	vpcs, err := describeVPCs(c.client)
+	if err != nil {
+		return nil, err
+	}
+
+	c.vpcs = vpcs
+	c.vpcsLastRefresh = time.Now()
+	return vpcs, nil
+}
+
+// OwnedVPCs returns the VPCs owned by the account, excluding the VPCs
+// shared with it by other accounts which count against the quotas of
+// their owner, or an error
+func (c *vpcsCache) OwnedVPCs() ([]*ec2.Vpc, error) {
+	accountID, err := c.accountID.AccountID()
+	if err != nil {
+		return nil, err
+	}
+
+	vpcs, err := c.VPCs()
+	if err != nil {
+		return nil, err
+	}
+
+	ownedVPCs := []*ec2.Vpc{}
+	for _, vpc := range vpcs {
+		if aws.StringValue(vpc.OwnerId) == accountID {
+			ownedVPCs = append(ownedVPCs, vpc)
+		}
+	}
+	return ownedVPCs, nil
+}
+
+// Subnets returns the subnets, describing them if the cached subnets
+// are older than the cache's TTL, or an error
+func (c *vpcsCache) Subnets() ([]*ec2.Subnet, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.subnets != nil && time.Since(c.subnetsLastRefresh) < c.ttl {
+		return c.subnets, nil
+	}
+
+	subnets, err := describeSubnets(c.client)
+	if err != nil {
+		return nil, err
+	}
+
+	c.subnets = subnets
+	c.subnetsLastRefresh = time.Now()
+	return subnets, nil
+}
+
+// RouteTables returns the route tables, describing them if the
+// cached route tables are older than the cache's TTL, or an error
+func (c *vpcsCache) RouteTables() ([]*ec2.RouteTable, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.routeTables != nil && time.Since(c.routeTablesLastRefresh) < c.ttl {
+		return c.routeTables, nil
+	}
+
+	routeTables, err := describeRouteTables(c.client)
+	if err != nil {
+		return nil, err
+	}
+
+	c.routeTables = routeTables
+	c.routeTablesLastRefresh = time.Now()
+	return routeTables, nil
+}
+
+func describeVPCs(client ec2iface.EC2API) ([]*ec2.Vpc, error) {
+	vpcs := []*ec2.Vpc{}
+	err := client.DescribeVpcsPages(&ec2.DescribeVpcsInput{},
+		func(page *ec2.DescribeVpcsOutput, lastPage bool) bool {
+			if page != nil {
+				vpcs = append(vpcs, page.Vpcs...)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return vpcs, nil
+}
+
+func describeSubnets(client ec2iface.EC2API) ([]*ec2.Subnet, error) {
+	subnets := []*ec2.Subnet{}
+	err := client.DescribeSubnetsPages(&ec2.DescribeSubnetsInput{},
+		func(page *ec2.DescribeSubnetsOutput, lastPage bool) bool {
+			if page != nil {
+				subnets = append(subnets, page.Subnets...)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return subnets, nil
+}
+
+func describeRouteTables(client ec2iface.EC2API) ([]*ec2.RouteTable, error) {
+	routeTables := []*ec2.RouteTable{}
+	err := client.DescribeRouteTablesPages(&ec2.DescribeRouteTablesInput{},
+		func(page *ec2.DescribeRouteTablesOutput, lastPage bool) bool {
+			if page != nil {
+				routeTables = append(routeTables, page.RouteTables...)
+			}
+			return !lastPage
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return routeTables, nil
+}
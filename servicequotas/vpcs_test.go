@@ -0,0 +1,116 @@
+package servicequotas
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVPCsCacheWithError(t *testing.T) {
+	mockClient := &mockEC2Client{err: errors.New("some err")}
+	cache := newVPCsCache(mockClient, testAccountID())
+
+	vpcs, err := cache.VPCs()
+
+	assert.Error(t, err)
+	assert.Nil(t, vpcs)
+
+	subnets, err := cache.Subnets()
+
+	assert.Error(t, err)
+	assert.Nil(t, subnets)
+}
+
+func TestVPCsCache(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeVpcsResponse: &ec2.DescribeVpcsOutput{
+			Vpcs: []*ec2.Vpc{{VpcId: aws.String("vpc-1"), OwnerId: aws.String("123456789012")}},
+		},
+		DescribeSubnetsResponse: &ec2.DescribeSubnetsOutput{
+			Subnets: []*ec2.Subnet{
+				{SubnetId: aws.String("subnet-1")},
+				{SubnetId: aws.String("subnet-2")},
+			},
+		},
+	}
+	cache := newVPCsCache(mockClient, testAccountID())
+
+	for i := 0; i < 3; i++ {
+		vpcs, err := cache.VPCs()
+
+		assert.NoError(t, err)
+		assert.Len(t, vpcs, 1)
+
+		subnets, err := cache.Subnets()
+
+		assert.NoError(t, err)
+		assert.Len(t, subnets, 2)
+	}
+	assert.Equal(t, 1, mockClient.DescribeVpcsCalls)
+	assert.Equal(t, 1, mockClient.DescribeSubnetsCalls)
+
+	cache.vpcsLastRefresh = time.Now().Add(-instancesCacheTTL)
+	_, err := cache.VPCs()
+	assert.NoError(t, err)
+	_, err = cache.Subnets()
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, mockClient.DescribeVpcsCalls)
+	assert.Equal(t, 1, mockClient.DescribeSubnetsCalls)
+}
+
+func TestVPCsCacheOwnedVPCs(t *testing.T) {
+	mockClient := &mockEC2Client{
+		DescribeVpcsResponse: &ec2.DescribeVpcsOutput{
+			Vpcs: []*ec2.Vpc{
+				{VpcId: aws.String("vpc-1"), OwnerId: aws.String("123456789012")},
+				{VpcId: aws.String("vpc-shared"), OwnerId: aws.String("210987654321")},
+			},
+		},
+	}
+	cache := newVPCsCache(mockClient, testAccountID())
+
+	vpcs, err := cache.OwnedVPCs()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*ec2.Vpc{{VpcId: aws.String("vpc-1"), OwnerId: aws.String("123456789012")}}, vpcs)
+
+	allVPCs, err := cache.VPCs()
+
+	assert.NoError(t, err)
+	assert.Len(t, allVPCs, 2)
+}
+
+func TestVPCsCacheOwnedVPCsWithAccountError(t *testing.T) {
+	mockClient := &mockEC2Client{}
+	cache := newVPCsCache(mockClient, newAccountIDCache(&mockSTSClient{err: errors.New("some err")}))
+
+	vpcs, err := cache.OwnedVPCs()
+
+	assert.Error(t, err)
+	assert.Nil(t, vpcs)
+	assert.Equal(t, 0, mockClient.DescribeVpcsCalls)
+}
+
+func TestVPCsCacheRouteTables(t *testing.T) {
+	mockClient := &mockEC2Client{DescribeRouteTablesResponse: testRouteTables()}
+	cache := newVPCsCache(mockClient, testAccountID())
+
+	for i := 0; i < 3; i++ {
+		routeTables, err := cache.RouteTables()
+
+		assert.NoError(t, err)
+		assert.Equal(t, testRouteTables().RouteTables, routeTables)
+	}
+	assert.Equal(t, 1, mockClient.DescribeRouteTablesCalls)
+
+	cache.routeTablesLastRefresh = time.Now().Add(-instancesCacheTTL)
+	_, err := cache.RouteTables()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, mockClient.DescribeRouteTablesCalls)
+}